@@ -0,0 +1,221 @@
+package hd
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// Account is a single account node m/44'/coin'/wallet' of a wallet, which can be handed over on its own: holding no
+// parent key, it cannot derive sibling accounts. It is safe for concurrent use.
+type Account struct {
+	key    *hdkeychain.ExtendedKey // private, or public if loaded with AccountFromXpub
+	index  uint32
+	coin   uint32
+	policy *policy // of the wallet the account came from, nil if loaded with AccountFromXpub
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Account returns the account node of 'wallet'. Accounts of watch-only wallets only hold the public key. The limits
+// of the wallet apply to exporting the account and, as they are shared with it, to the addresses later derived from
+// it. SetLimits on the wallet affects its accounts too.
+func (w *HdWallet) Account(wallet uint32) (*Account, error) {
+	if wallet >= hardened {
+		return nil, ErrIndexOutOfRange
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil, ErrClosed
+	}
+
+	if err := w.policy.allowDerivation(wallet, 0); err != nil {
+		return nil, err
+	}
+
+	k, err := w.account(wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	// the account key of a watch-only wallet is shared, copy it
+	if !k.IsPrivate() {
+		if k, err = copyKey(k); err != nil {
+			return nil, err
+		}
+	}
+
+	// memoize the public key, so that concurrent derivations only read the key
+	if _, err = k.ECPubKey(); err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return &Account{key: k, index: wallet, coin: w.coin, policy: &w.policy}, nil
+}
+
+// AccountFromXpub loads an Ethereum account from its extended public key, e.g. as returned by Account.Xpub or
+// HdWallet.ExportXpub. It can only derive the non-hardened addresses of PublicAddress.
+func AccountFromXpub(xpub string) (*Account, error) {
+	return AccountFromXpubForCoin(xpub, CoinETH)
+}
+
+// AccountFromXpubForCoin loads an account of coinType from its extended public key, like AccountFromXpub.
+func AccountFromXpubForCoin(xpub string, coinType uint32) (*Account, error) {
+	k, err := parseAccountXpub(xpub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{key: k, index: k.ChildIndex() - hdkeychain.HardenedKeyStart, coin: coinType}, nil
+}
+
+// Index returns the wallet number of the account.
+func (a *Account) Index() uint32 {
+	return a.index
+}
+
+// Coin returns the SLIP-44 coin type of the account.
+func (a *Account) Coin() uint32 {
+	return a.coin
+}
+
+// Xprv returns the serialized extended private key of the account, or "" if the account only holds the public key.
+// As the key would lift them, it is also "" while the wallet the account came from has limits.
+func (a *Account) Xprv() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed || !a.key.IsPrivate() || (a.policy != nil && a.policy.limited()) {
+		return ""
+	}
+
+	return a.key.String()
+}
+
+// Xpub returns the serialized extended public key of the account, or "" once closed.
+func (a *Account) Xpub() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return ""
+	}
+
+	pub, err := a.key.Neuter()
+	if err != nil {
+		return ""
+	}
+
+	return pub.String()
+}
+
+// Address generates the address of flg and address number with a hardened address number, like HdWallet.Address.
+func (a *Account) Address(flg uint8, addrNum uint32) (addr, key []byte, prv ecdsa.PrivateKey, err error) {
+	if addrNum >= hardened {
+		err = ErrIndexOutOfRange
+
+		return
+	}
+
+	k, err := a.derive(flg, hdkeychain.HardenedKeyStart+addrNum, true)
+	if err != nil {
+		return
+	}
+	defer k.Zero()
+
+	addr, key, prv = coinKeys(a.coin, k)
+
+	return
+}
+
+// AddressBIP44 generates the address of flg and address number following BIP44, like HdWallet.AddressBIP44.
+func (a *Account) AddressBIP44(flg uint8, addrNum uint32) (addr, key []byte, prv ecdsa.PrivateKey, err error) {
+	if addrNum >= hardened {
+		err = ErrIndexOutOfRange
+
+		return
+	}
+
+	k, err := a.derive(flg, addrNum, true)
+	if err != nil {
+		return
+	}
+	defer k.Zero()
+
+	addr, key, prv = coinKeys(a.coin, k)
+
+	return
+}
+
+// PublicAddress generates the address of AddressBIP44 using public derivation only, so it works on accounts loaded
+// with AccountFromXpub too.
+func (a *Account) PublicAddress(flg uint8, addrNum uint32) ([]byte, error) {
+	if addrNum >= hardened {
+		return nil, ErrIndexOutOfRange
+	}
+
+	k, err := a.derive(flg, addrNum, false)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Zero()
+
+	pub, err := k.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return coinAddress(a.coin, pub), nil
+}
+
+// Close wipes the key of the account. Any later derivation returns ErrClosed.
+func (a *Account) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.closed {
+		a.closed = true
+		a.key.Zero()
+	}
+
+	return nil
+}
+
+// derive returns the key at m/44'/coin'/index'/flg/child. private requires the account to hold the private key.
+func (a *Account) derive(flg uint8, child uint32, private bool) (*hdkeychain.ExtendedKey, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return nil, ErrClosed
+	}
+
+	if private && !a.key.IsPrivate() {
+		return nil, ErrWatchOnly
+	}
+
+	if flg != External && flg != Change {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidFlag, flg)
+	}
+
+	if a.policy != nil {
+		if err := a.policy.allowDerivation(a.index, child&^hardened); err != nil {
+			return nil, err
+		}
+	}
+
+	p := Path{hardened + purpose, hardened + a.coin, hardened + a.index, uint32(flg), child}
+
+	br, err := deriveChild(a.key, p, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer br.Zero()
+
+	return deriveChild(br, p, 4)
+}
@@ -0,0 +1,125 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAccount(t *testing.T) {
+	w := testWallet(t)
+
+	a, err := w.Account(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := AccountFromXpub(a.Xpub())
+	if err != nil || pub.Index() != 2 || pub.Coin() != CoinETH {
+		t.Fatalf("AccountFromXpub. Got:%d %d, err:%v", pub.Index(), pub.Coin(), err)
+	}
+
+	if xpub, _ := w.ExportXpub(2); a.Xpub() != xpub || pub.Xpub() != xpub {
+		t.Errorf("Xpub does not match. Got:%s, expected:%s", a.Xpub(), xpub)
+	}
+
+	for _, flg := range []uint8{External, Change} {
+		for i := uint32(0); i < 3; i++ {
+			exp, _, _, _ := w.Address(2, flg, i)
+			if addr, _, _, err := a.Address(flg, i); err != nil || !bytes.Equal(addr, exp) {
+				t.Errorf("Address does not match. Got:%x, expected:%x, err:%v", addr, exp, err)
+			}
+
+			exp, _, _, _ = w.AddressBIP44(2, flg, i)
+			if addr, _, _, err := a.AddressBIP44(flg, i); err != nil || !bytes.Equal(addr, exp) {
+				t.Errorf("BIP44 address does not match. Got:%x, expected:%x, err:%v", addr, exp, err)
+			}
+
+			if addr, err := pub.PublicAddress(flg, i); err != nil || !bytes.Equal(addr, exp) {
+				t.Errorf("Public address does not match. Got:%x, expected:%x, err:%v", addr, exp, err)
+			}
+		}
+	}
+
+	if pub.Xprv() != "" || a.Xprv() == "" {
+		t.Errorf("Xprv. Got:%q, expected private key only for the private account", pub.Xprv())
+	}
+
+	if _, _, _, err = pub.Address(External, 0); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+
+	if _, _, _, err = a.Address(External, hardened); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	if _, err = pub.PublicAddress(2, 0); !errors.Is(err, ErrInvalidFlag) {
+		t.Errorf("Expected ErrInvalidFlag, got %v", err)
+	}
+
+	a.Close()
+
+	if _, _, _, err = a.Address(External, 0); !errors.Is(err, ErrClosed) || a.Xprv() != "" {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+func TestAccountSiblings(t *testing.T) {
+	w := testWallet(t)
+
+	a, _ := w.Account(2)
+	defer a.Close()
+
+	// the account only holds its own node, the parent needed for siblings is gone
+	if a.key.Depth() != 3 || a.key.ChildIndex() != hardened+2 {
+		t.Fatalf("Account node. Got depth %d index %x", a.key.Depth(), a.key.ChildIndex())
+	}
+
+	mine, _, _, _ := a.Address(External, 0)
+
+	for _, wallet := range []uint32{0, 1, 3} {
+		sibling, _, _, _ := w.Address(wallet, External, 0)
+		if bytes.Equal(mine, sibling) {
+			t.Errorf("Account %d reached wallet %d: %x", a.Index(), wallet, sibling)
+		}
+	}
+
+	// the account number is checked by the policy of the wallet
+	lw := testWallet(t, WithLimits(Limits{AllowedAccounts: []uint32{2}}))
+	if _, err := lw.Account(1); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	// and so are the addresses derived from its accounts
+	lw.SetLimits(Limits{MaxIndex: 5, AllowedAccounts: []uint32{2}})
+
+	la, err := lw.Account(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err = la.Address(External, 5); err != nil {
+		t.Errorf("Address: %v", err)
+	}
+
+	if _, _, _, err = la.AddressBIP44(External, 6); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("AddressBIP44. Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	if _, err = la.PublicAddress(Change, 1000); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("PublicAddress. Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	if la.Xprv() != "" || la.Xpub() == "" {
+		t.Errorf("Xprv of an account with limits. Got:%s", la.Xprv())
+	}
+
+	wo, _ := w.Neuter(2)
+	if acct, err := wo.Account(2); err != nil || acct.Xprv() != "" || acct.Xpub() != a.Xpub() {
+		t.Errorf("Watch-only account. Got:%s, err:%v", acct.Xpub(), err)
+	}
+
+	if _, err := AccountFromXpub(a.Xprv()); !errors.Is(err, ErrInvalidXpub) {
+		t.Errorf("Expected ErrInvalidXpub, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package hd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const ethAddressLen = 20
+
+var (
+	// ErrUnsupportedCoin will be reported when an operation does not apply to the coin of the wallet.
+	ErrUnsupportedCoin error = errors.New("hd: operation not supported for the coin")
+	// ErrInvalidAddress will be reported for strings that are not 0x-prefixed 20-byte hex addresses.
+	ErrInvalidAddress error = errors.New("hd: invalid address")
+	// ErrInvalidAddressChecksum will be reported for mixed-case addresses whose EIP-55 checksum does not match.
+	ErrInvalidAddressChecksum error = errors.New("hd: invalid address checksum")
+)
+
+// AddressHex generates the address for 'wallet', flg and address number like Address does, as a 0x-prefixed EIP-55
+// checksummed string. It is only available for Ethereum and Ethereum Classic wallets.
+func (w *HdWallet) AddressHex(wallet uint32, flg uint8, addrNum uint32) (string, error) {
+	if w.coin != CoinETH && w.coin != CoinETC {
+		return "", ErrUnsupportedCoin
+	}
+
+	addr, key, _, err := w.Address(wallet, flg, addrNum)
+	zero(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	return ChecksumAddress(addr), nil
+}
+
+// ChecksumAddress returns the 0x-prefixed EIP-55 mixed-case encoding of a 20-byte Ethereum address.
+func ChecksumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	hash := keccak256([]byte(lower))
+	out := []byte(lower)
+
+	for i, c := range out {
+		// upper-case a letter when the matching nibble of the hash is 8 or more
+		if c >= 'a' && hash[i/2]>>(4*(1-i%2))&0x0f >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(out)
+}
+
+// ValidateAddress checks s is a 0x-prefixed Ethereum address. All-lower and all-upper case addresses carry no
+// checksum and are accepted; mixed-case ones must match their EIP-55 checksum.
+func ValidateAddress(s string) error {
+	if len(s) != 2+2*ethAddressLen || !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return ErrInvalidAddress
+	}
+
+	addr, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, err.Error())
+	}
+
+	if digits := s[2:]; digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+
+	if ChecksumAddress(addr)[2:] != s[2:] {
+		return ErrInvalidAddressChecksum
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package hd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddressHex(t *testing.T) {
+	w := testWallet(t)
+
+	// the iancoleman vectors of TestHdWallet, which are checksummed
+	for i, exp := range []string{
+		"0xD43E2870777916Ede1f5Cc43F14f8C0741e11f96",
+		"0xF4cEFC8d1AfaA51d5A5E7f57d214B60429cA4378",
+		"0x8A1847459c5FCD66f0B29012a21A2D5A314Ef1D0",
+	} {
+		if addr, err := w.AddressHex(2, External, uint32(i)); err != nil || addr != exp {
+			t.Errorf("Address %d does not match. Got:%s, expected:%s, err:%v", i, addr, exp, err)
+		}
+	}
+
+	seed, _ := SeedFromMnemonic(testMnemonic, "password")
+
+	btc, _ := InitForCoin(seed, CoinBTC)
+	if _, err := btc.AddressHex(2, External, 0); !errors.Is(err, ErrUnsupportedCoin) {
+		t.Errorf("Expected ErrUnsupportedCoin, got %v", err)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	// EIP-55 test vectors
+	for _, s := range []string{
+		"0x52908400098527886E0F7030069857D2E4169EE7",
+		"0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+		"0xde709f2102306220921060314715629080e2fb77",
+		"0x27b1fdb04752bbc536007a920d24acb045561c26",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		if err := ValidateAddress(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+
+	tests := []struct {
+		s   string
+		err error
+	}{
+		{"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrInvalidAddress},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", ErrInvalidAddress},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAzz", ErrInvalidAddress},
+		{"0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrInvalidAddressChecksum},
+		{"0x" + strings.Repeat("a", 39) + "B", ErrInvalidAddressChecksum},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateAddress(tt.s); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.s, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package hd
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const batchPrealloc = 1 << 16 // addresses allocated up front by a batch, larger ones grow as they are derived
+
+// AddressInfo is an address derived by Addresses or AddressesBIP44.
+type AddressInfo struct {
+	Index   uint32 // address number
+	Address []byte
+	Key     []byte // private key, only set when requested
+}
+
+// Addresses generates count addresses of 'wallet' and flg from address number start, as Address would, deriving the
+// account and flg branch only once. Private keys are included if withKeys is set. Address numbers of 2^31 or more
+// report ErrIndexOutOfRange, or wrap around as with Address without strict mode, see WithStrictMode.
+// If a derivation fails the addresses derived so far are returned along with the error, so the failing address
+// number is start+len(addresses).
+func (w *HdWallet) Addresses(wallet uint32, flg uint8, start, count uint32, withKeys bool) ([]AddressInfo, error) {
+	if w.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	return w.addresses(wallet, flg, start, count, withKeys, hdkeychain.HardenedKeyStart)
+}
+
+// AddressesBIP44 generates count addresses like Addresses but following BIP44, as AddressBIP44 would. Without
+// withKeys it also works on watch-only wallets.
+func (w *HdWallet) AddressesBIP44(wallet uint32, flg uint8, start, count uint32, withKeys bool,
+) ([]AddressInfo, error) {
+	if withKeys && w.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	return w.addresses(wallet, flg, start, count, withKeys, 0)
+}
+
+// addresses derives the batch, offset being added to each address number to get the child index.
+func (w *HdWallet) addresses(wallet uint32, flg uint8, start, count uint32, withKeys bool, offset uint32,
+) ([]AddressInfo, error) {
+	br, release, err := w.branch(wallet, flg)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// address numbers end at 2^31, or wrap around up to 2^32 as Address does without strict mode
+	end := uint64(hardened)
+	if w.lenient && offset == hdkeychain.HardenedKeyStart {
+		end = 1 << 32
+	}
+
+	// at most the address numbers left, and a bounded amount so that a huge count cannot exhaust memory
+	var size uint64
+	if uint64(start) < end {
+		size = uint64(count)
+		if size > end-uint64(start) {
+			size = end - uint64(start)
+		}
+
+		if size > batchPrealloc {
+			size = batchPrealloc
+		}
+	}
+
+	infos := make([]AddressInfo, 0, size)
+
+	for i := uint64(start); i < uint64(start)+uint64(count); i++ {
+		if i >= end {
+			return infos, fmt.Errorf("hd: address %d: %w", i, ErrIndexOutOfRange)
+		}
+
+		addrNum := uint32(i)
+
+		k, err := w.leaf(br, wallet, addrNum, offset+addrNum)
+		if err != nil {
+			return infos, fmt.Errorf("hd: address %d: %w", addrNum, err)
+		}
+
+		info := AddressInfo{Index: addrNum}
+
+		if withKeys {
+			info.Address, info.Key, _ = w.keys(k)
+		} else {
+			pub, err := k.ECPubKey()
+			if err != nil {
+				return infos, fmt.Errorf("hd: address %d: %w", addrNum, err)
+			}
+
+			info.Address = w.encodeAddress(pub)
+		}
+
+		k.Zero()
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
@@ -0,0 +1,118 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAddresses(t *testing.T) {
+	w := testWallet(t)
+
+	infos, err := w.Addresses(2, External, 0, 3, true)
+	if err != nil || len(infos) != 3 {
+		t.Fatalf("Addresses: %d, %v", len(infos), err)
+	}
+
+	bip44, err := w.AddressesBIP44(2, Change, 5, 3, false)
+	if err != nil || len(bip44) != 3 {
+		t.Fatalf("AddressesBIP44: %d, %v", len(bip44), err)
+	}
+
+	for i := uint32(0); i < 3; i++ {
+		addr, key, _, _ := w.Address(2, External, i)
+		if infos[i].Index != i || !bytes.Equal(infos[i].Address, addr) || !bytes.Equal(infos[i].Key, key) {
+			t.Errorf("Address %d does not match. Got:%+v, expected:%x", i, infos[i], addr)
+		}
+
+		addr, _, _, _ = w.AddressBIP44(2, Change, 5+i)
+		if bip44[i].Index != 5+i || !bytes.Equal(bip44[i].Address, addr) || bip44[i].Key != nil {
+			t.Errorf("BIP44 address %d does not match. Got:%+v, expected:%x", 5+i, bip44[i], addr)
+		}
+	}
+
+	// watch-only wallets can batch public addresses only
+	neutered, _ := w.Neuter(2)
+
+	pub, err := neutered.AddressesBIP44(2, Change, 5, 3, false)
+	if err != nil || !bytes.Equal(pub[2].Address, bip44[2].Address) {
+		t.Errorf("Watch-only AddressesBIP44 does not match: %v", err)
+	}
+
+	if _, err = neutered.AddressesBIP44(2, Change, 5, 3, true); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+
+	if _, err = neutered.Addresses(2, Change, 5, 3, false); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+}
+
+func TestAddressesPartial(t *testing.T) {
+	// a failure returns what was derived, and the failing index is start+len
+	w := testWallet(t, WithLimits(Limits{MaxIndex: 9}))
+
+	infos, err := w.Addresses(0, External, 7, 5, false)
+	if !errors.Is(err, ErrPolicyExceeded) || len(infos) != 3 || infos[2].Index != 9 {
+		t.Errorf("Expected 3 addresses and ErrPolicyExceeded, got %d, %v", len(infos), err)
+	}
+
+	infos, err = testWallet(t).AddressesBIP44(0, External, hardened-2, 5, false)
+	if !errors.Is(err, ErrIndexOutOfRange) || len(infos) != 2 {
+		t.Errorf("Expected 2 addresses and ErrIndexOutOfRange, got %d, %v", len(infos), err)
+	}
+
+	// without strict mode address numbers wrap around as with Address, but not past 2^32
+	lenient := testWallet(t, WithStrictMode(false))
+
+	infos, err = lenient.Addresses(0, External, hardened-1, 2, false)
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("Expected 2 addresses, got %d, %v", len(infos), err)
+	}
+
+	for _, info := range infos {
+		if exp, _, _, _ := lenient.Address(0, External, info.Index); !bytes.Equal(info.Address, exp) {
+			t.Errorf("Address %d does not match. Got:%x, expected:%x", info.Index, info.Address, exp)
+		}
+	}
+
+	if infos, err = lenient.Addresses(0, External, math.MaxUint32, 2, false); !errors.Is(err, ErrIndexOutOfRange) ||
+		len(infos) != 1 {
+		t.Errorf("Expected 1 address and ErrIndexOutOfRange, got %d, %v", len(infos), err)
+	}
+
+	if _, err = lenient.AddressesBIP44(0, External, hardened, 1, false); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	// a huge count is not allocated up front
+	infos, err = testWallet(t).Addresses(0, External, hardened-2, math.MaxUint32, false)
+	if !errors.Is(err, ErrIndexOutOfRange) || len(infos) != 2 || cap(infos) > 2 {
+		t.Errorf("Expected 2 addresses and ErrIndexOutOfRange, got %d (cap %d), %v", len(infos), cap(infos), err)
+	}
+}
+
+const benchAddresses = 10000
+
+func BenchmarkAddresses(b *testing.B) {
+	w := testWallet(b)
+
+	for n := 0; n < b.N; n++ {
+		if _, err := w.Addresses(0, External, 0, benchAddresses, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAddressLoop(b *testing.B) {
+	w := testWallet(b)
+
+	for n := 0; n < b.N; n++ {
+		for i := uint32(0); i < benchAddresses; i++ {
+			if _, _, _, err := w.Address(0, External, i); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package hd
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const (
+	bip85Purpose  uint32 = 83696968 // BIP85 purpose, the first component of its paths
+	bip85BIP39    uint32 = 39       // BIP85 application for BIP39 mnemonics
+	bip85English  uint32 = 0        // BIP85 language code of the English wordlist
+	bip85MaxBytes        = sha512.Size
+)
+
+// ErrNoMasterKey will be reported by BIP85 derivations on wallets not initialized from a seed with WithMasterKey.
+var ErrNoMasterKey error = errors.New("hd: master key not retained, use WithMasterKey")
+
+// WithMasterKey retains the master key of the seed in the wallet, which BIP85 derivations need. The master key reaches
+// every coin and account of the seed, so only use it in wallets that derive BIP85 entropy. It has no effect on wallets
+// not initialized from a seed.
+func WithMasterKey() Option {
+	return func(w *HdWallet) {
+		w.keepMaster = true
+	}
+}
+
+// BIP85Entropy derives n bytes, at most 64, of BIP85 entropy at the absolute path s, which must start with
+// m/83696968' and only have hardened components. BIP85 entropy is derived from the master key, beyond the accounts
+// the limits of the wallet are about, so it is refused with ErrPolicyExceeded while the wallet has limits.
+func (w *HdWallet) BIP85Entropy(s string, n int) ([]byte, error) {
+	p, err := ParsePath(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.bip85Entropy(p, n)
+}
+
+// BIP85Mnemonic derives the child BIP39 mnemonic of 12, 18 or 24 English words with the given index, following the
+// BIP85 application at m/83696968'/39'/0'/words'/index'. As BIP85Entropy, it is refused while the wallet has limits.
+func (w *HdWallet) BIP85Mnemonic(words, index uint32) (string, error) {
+	if words != 12 && words != 18 && words != 24 {
+		return "", fmt.Errorf("%w: %d", ErrInvalidWordCount, words)
+	}
+
+	if index >= hardened {
+		return "", ErrIndexOutOfRange
+	}
+
+	p := Path{hardened + bip85Purpose, hardened + bip85BIP39, hardened + bip85English, hardened + words, hardened + index}
+
+	entropy, err := w.bip85Entropy(p, int(words)*4/3)
+	if err != nil {
+		return "", err
+	}
+	defer zero(entropy)
+
+	return MnemonicFromEntropy(entropy)
+}
+
+// bip85Entropy checks p and derives n bytes of entropy at it from the retained master key.
+func (w *HdWallet) bip85Entropy(p Path, n int) ([]byte, error) {
+	if len(p) < 2 || p[0] != hardened+bip85Purpose {
+		return nil, fmt.Errorf("%w: %s is not a BIP85 path", ErrInvalidPath, p)
+	}
+
+	for _, c := range p {
+		if c < hardened {
+			return nil, fmt.Errorf("%w: %s", ErrNotHardened, p)
+		}
+	}
+
+	if n < 1 || n > bip85MaxBytes {
+		return nil, ErrInvalidEntropyLen
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil, ErrClosed
+	}
+
+	if w.master == nil {
+		return nil, ErrNoMasterKey
+	}
+
+	if w.policy.limited() {
+		return nil, fmt.Errorf("%w: BIP85 entropy of a wallet with limits", ErrPolicyExceeded)
+	}
+
+	return bip85Entropy(w.master, p, n)
+}
+
+// bip85Entropy derives the key at p from master and returns the first n bytes of its HMAC-SHA512 entropy.
+func bip85Entropy(master *hdkeychain.ExtendedKey, p Path, n int) ([]byte, error) {
+	k, owned := master, false
+
+	for i := range p {
+		next, err := deriveChild(k, p, i)
+		if owned {
+			k.Zero()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		k, owned = next, true
+	}
+	defer k.Zero()
+
+	prv, err := k.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+	defer prv.Zero()
+
+	key := prv.Serialize()
+	defer zero(key)
+
+	mac := hmac.New(sha512.New, []byte("bip-entropy-from-k"))
+	mac.Write(key)
+
+	return mac.Sum(nil)[:n], nil
+}
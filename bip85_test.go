@@ -0,0 +1,137 @@
+package hd
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// bip85Master is the master key of the BIP85 test vectors.
+const bip85Master = "xprv9s21ZrQH143K2LBWUUQRFXhucrQqBpKdRRxNVq2zBqsx8HVqFk2uYo8k" +
+	"mbaLLHRdqtQpUm98uKfu3vca1LqdGhUtyoFnCNkfmXRyPXLjbKb"
+
+func bip85Wallet(t *testing.T) *HdWallet {
+	t.Helper()
+
+	master, err := hdkeychain.NewKeyFromString(bip85Master)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &HdWallet{master: master}
+}
+
+func TestBIP85Entropy(t *testing.T) {
+	w := bip85Wallet(t)
+
+	tests := []struct {
+		path    string
+		entropy string
+	}{
+		{"m/83696968'/0'/0'", "efecfbccffea313214232d29e71563d941229afb4338c21f9517c41aaa0d16f00b83d2a09ef747e7a64e8e2bd5a1" +
+			"4869e693da66ce94ac2da570ab7ee48618f7"},
+		{"m/83696968'/0'/1'", "70c6e3e8ebee8dc4c0dbba66076819bb8c09672527c4277ca8729532ad711872218f826919f6b67218adde99018a" +
+			"6df9095ab2b58d803b5b93ec9802085a690e"},
+	}
+
+	for _, tt := range tests {
+		entropy, err := w.BIP85Entropy(tt.path, 64)
+		if err != nil {
+			t.Fatalf("%s: BIP85Entropy %e", tt.path, err)
+		}
+
+		if hex.EncodeToString(entropy) != tt.entropy {
+			t.Errorf("%s: Got:%x, expected:%s", tt.path, entropy, tt.entropy)
+		}
+	}
+
+	errs := []struct {
+		path string
+		n    int
+		err  error
+	}{
+		{"m/44'/0'/0'", 64, ErrInvalidPath},
+		{"m/83696968'/0'/0", 64, ErrNotHardened},
+		{"m/83696968'/0'/0'", 65, ErrInvalidEntropyLen},
+		{"m/83696968'/0'/0'", 0, ErrInvalidEntropyLen},
+	}
+
+	for _, tt := range errs {
+		if _, err := w.BIP85Entropy(tt.path, tt.n); !errors.Is(err, tt.err) {
+			t.Errorf("%s %d: expected %v, got %v", tt.path, tt.n, tt.err, err)
+		}
+	}
+}
+
+func TestBIP85Mnemonic(t *testing.T) {
+	w := bip85Wallet(t)
+
+	tests := []struct {
+		words    uint32
+		mnemonic string
+	}{
+		{12, "girl mad pet galaxy egg matter matrix prison refuse sense ordinary nose"},
+		{18, "near account window bike charge season chef number sketch tomorrow excuse sniff circle vital hockey " +
+			"outdoor supply token"},
+		{24, "puppy ocean match cereal symbol another shed magic wrap hammer bulb intact gadget divorce twin tonight " +
+			"reason outdoor destroy simple truth cigar social volcano"},
+	}
+
+	for _, tt := range tests {
+		if m, err := w.BIP85Mnemonic(tt.words, 0); err != nil || m != tt.mnemonic {
+			t.Errorf("%d words: Got:%s, expected:%s, err:%v", tt.words, m, tt.mnemonic, err)
+		}
+	}
+
+	if _, err := w.BIP85Mnemonic(15, 0); !errors.Is(err, ErrInvalidWordCount) {
+		t.Errorf("Expected ErrInvalidWordCount, got %v", err)
+	}
+
+	if _, err := w.BIP85Mnemonic(12, hardened); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
+
+func TestWithMasterKey(t *testing.T) {
+	w := testWallet(t, WithMasterKey())
+
+	m1, err := w.BIP85Mnemonic(12, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// child mnemonics are valid and differ per index
+	if m2, _ := w.BIP85Mnemonic(12, 1); ValidateMnemonic(m1) != nil || m1 == m2 {
+		t.Errorf("Invalid child mnemonics %q %q", m1, m2)
+	}
+
+	// the master key is only retained on request, and wiped by Close
+	if _, err = testWallet(t).BIP85Mnemonic(12, 0); !errors.Is(err, ErrNoMasterKey) {
+		t.Errorf("Expected ErrNoMasterKey, got %v", err)
+	}
+
+	// the master key reaches beyond the limits of the wallet
+	w.SetLimits(Limits{AllowedAccounts: []uint32{0}})
+
+	if _, err = w.BIP85Mnemonic(12, 0); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	if _, err = w.BIP85Entropy("m/83696968'/128169'/32'/0'", 32); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	w.SetLimits(Limits{})
+
+	if m, err := w.BIP85Mnemonic(12, 0); err != nil || m != m1 {
+		t.Errorf("Got:%q, expected:%q, err:%v", m, m1, err)
+	}
+
+	w.Close()
+
+	if _, err = w.BIP85Mnemonic(12, 0); !errors.Is(err, ErrClosed) || w.master.Depth() != 0 || w.master.IsPrivate() {
+		t.Errorf("Expected ErrClosed and a wiped master key, got %v", err)
+	}
+}
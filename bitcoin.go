@@ -0,0 +1,112 @@
+package hd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// AddrFormat is a Bitcoin address format.
+type AddrFormat uint8
+
+const (
+	// P2PKH is the legacy base58check pay-to-pubkey-hash format, "1..." on mainnet, "m..." or "n..." on testnet.
+	P2PKH AddrFormat = iota
+	// P2WPKH is the native segwit bech32 pay-to-witness-pubkey-hash format, "bc1..." on mainnet, "tb1..." on testnet.
+	P2WPKH
+)
+
+// ErrInvalidAddrFormat will be reported for unknown address formats.
+var ErrInvalidAddrFormat error = errors.New("hd: invalid address format")
+
+// BTCAddress returns the Bitcoin address of the BIP44 key m/44'/coin'/wallet'/flg/index in the given format, for
+// wallets initialized with CoinBTC or CoinTestnet. It also works on watch-only wallets.
+func (w *HdWallet) BTCAddress(wallet uint32, flg uint8, index uint32, format AddrFormat) (string, error) {
+	net, err := w.btcParams()
+	if err != nil {
+		return "", err
+	}
+
+	if index >= hardened {
+		return "", ErrIndexOutOfRange
+	}
+
+	k, err := w.derive(wallet, flg, index, index)
+	if err != nil {
+		return "", err
+	}
+	defer k.Zero()
+
+	pub, err := k.ECPubKey()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	pkHash := btcutil.Hash160(pub.SerializeCompressed())
+
+	var addr btcutil.Address
+
+	switch format {
+	case P2PKH:
+		addr, err = btcutil.NewAddressPubKeyHash(pkHash, net)
+	case P2WPKH:
+		addr, err = btcutil.NewAddressWitnessPubKeyHash(pkHash, net)
+	default:
+		return "", ErrInvalidAddrFormat
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return addr.EncodeAddress(), nil
+}
+
+// WIF returns the private key of the BIP44 key m/44'/coin'/wallet'/flg/index in compressed wallet import format, for
+// wallets initialized with CoinBTC or CoinTestnet.
+func (w *HdWallet) WIF(wallet uint32, flg uint8, index uint32) (string, error) {
+	net, err := w.btcParams()
+	if err != nil {
+		return "", err
+	}
+
+	_, key, _, err := w.AddressBIP44(wallet, flg, index)
+	if err != nil {
+		return "", err
+	}
+	defer zero(key)
+
+	prv, _ := btcec.PrivKeyFromBytes(key)
+	defer prv.Zero()
+
+	wif, err := btcutil.NewWIF(prv, net, true)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return wif.String(), nil
+}
+
+// btcParams returns the Bitcoin network of the wallet, the one set with WithNetwork, e.g. regtest, or else the
+// network of its coin.
+func (w *HdWallet) btcParams() (*chaincfg.Params, error) {
+	var net *chaincfg.Params
+
+	switch w.coin {
+	case CoinBTC:
+		net = &chaincfg.MainNetParams
+	case CoinTestnet:
+		net = &chaincfg.TestNet3Params
+	default:
+		return nil, ErrUnsupportedCoin
+	}
+
+	if w.net != nil {
+		return w.net, nil
+	}
+
+	return net, nil
+}
@@ -0,0 +1,125 @@
+package hd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestBTCAddress(t *testing.T) {
+	// iancoleman.io, BIP44 tab
+	seed, _ := SeedFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon "+
+		"abandon about", "")
+
+	tests := []struct {
+		coin               uint32
+		wallet, index      uint32
+		flg                uint8
+		p2pkh, p2wpkh, wif string
+	}{
+		{CoinBTC, 0, 0, External, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", "bc1qmxrw6qdh5g3ztfcwm0et5l8mvws4eva24kmp8m",
+			"L4p2b9VAf8k5aUahF1JCJUzZkgNEAqLfq8DDdQiyAprQAKSbu8hf"},
+		{CoinBTC, 0, 1, External, "1Ak8PffB2meyfYnbXZR9EGfLfFZVpzJvQP", "bc1qdtsnq885fjjj2agaza36cnl0ztg32wvxqg5x0c",
+			"KzJgGiEeGUVWmPR97pVWDnCVraZvM2fnrCVrg2irV4353HciE6Un"},
+		{CoinBTC, 1, 3, Change, "1GbfPnNKuK4qKJMM7M2M6DXbS9GH6n8y6N", "bc1q4vvpgqd90ya0l9n8w5w0kcqlz8z4zywdkeuj39",
+			"Kz4Jj5h8tSPNButZkLFrZ6NcWAeVmEgYWg2uh3tL1nGHSAWCVUnn"},
+		{CoinTestnet, 0, 0, External, "mkpZhYtJu2r87Js3pDiWJDmPte2NRZ8bJV", "tb1q8gk5z3dy7zv9ywe7synlrk58elz4hrne29cpdj",
+			"cV6NTLu255SZ5iCNkVHezNGDH5qv6CanJpgBPqYgJU13NNKJhRs1"},
+		{CoinTestnet, 0, 1, External, "mzpbWabUQm1w8ijuJnAof5eiSTep27deVH", "tb1q60qgwr5wzw57cvs0z2qg3yf84g26fs9p97f2cu",
+			"cUATcNZMgKQn5vUYuVvKVnoQUKcvyJuZvHyHFHfoi5mm4E1T7Gs3"},
+		{CoinTestnet, 1, 3, Change, "mumCC5n2rAeRbW2Z5uKpCXzjzdTo1VPqdu", "tb1qn3pj7cavxrxhk943z55rzydnphkxrvcrz6w9tc",
+			"cNN52foGQaH97RmdZxHZa6YEyUqUq53bUeH5pW2TxrCjiaXnoBXu"},
+	}
+
+	for _, tt := range tests {
+		w, err := InitForCoin(seed, tt.coin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr, err := w.BTCAddress(tt.wallet, tt.flg, tt.index, P2PKH); err != nil || addr != tt.p2pkh {
+			t.Errorf("P2PKH does not match. Got:%s, expected:%s, err:%v", addr, tt.p2pkh, err)
+		}
+
+		if addr, err := w.BTCAddress(tt.wallet, tt.flg, tt.index, P2WPKH); err != nil || addr != tt.p2wpkh {
+			t.Errorf("P2WPKH does not match. Got:%s, expected:%s, err:%v", addr, tt.p2wpkh, err)
+		}
+
+		if got, err := w.WIF(tt.wallet, tt.flg, tt.index); err != nil || got != tt.wif {
+			t.Errorf("WIF does not match. Got:%s, expected:%s, err:%v", got, tt.wif, err)
+		}
+
+		// watch-only wallets derive the same addresses
+		wo, _ := w.Neuter(tt.wallet)
+		if addr, err := wo.BTCAddress(tt.wallet, tt.flg, tt.index, P2PKH); err != nil || addr != tt.p2pkh {
+			t.Errorf("Watch-only P2PKH does not match. Got:%s, expected:%s, err:%v", addr, tt.p2pkh, err)
+		}
+
+		if _, err := wo.WIF(tt.wallet, tt.flg, tt.index); !errors.Is(err, ErrWatchOnly) {
+			t.Errorf("Expected ErrWatchOnly, got %v", err)
+		}
+	}
+
+	w, _ := InitForCoin(seed, CoinBTC)
+	if _, err := w.BTCAddress(0, External, 0, AddrFormat(9)); !errors.Is(err, ErrInvalidAddrFormat) {
+		t.Errorf("Expected ErrInvalidAddrFormat, got %v", err)
+	}
+
+	if _, err := w.BTCAddress(0, External, hardened, P2PKH); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	eth, _ := Init(seed)
+	if _, err := eth.BTCAddress(0, External, 0, P2PKH); !errors.Is(err, ErrUnsupportedCoin) {
+		t.Errorf("Expected ErrUnsupportedCoin, got %v", err)
+	}
+
+	if _, err := eth.WIF(0, External, 0); !errors.Is(err, ErrUnsupportedCoin) {
+		t.Errorf("Expected ErrUnsupportedCoin, got %v", err)
+	}
+}
+
+func TestBTCAddressRegtest(t *testing.T) {
+	seed, _ := SeedFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon "+
+		"abandon about", "")
+
+	w, err := InitForCoin(seed, CoinTestnet, WithNetwork(&chaincfg.RegressionNetParams))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// regtest shares the testnet prefixes, but for bech32
+	if addr, err := w.BTCAddress(0, External, 0, P2PKH); err != nil || addr != "mkpZhYtJu2r87Js3pDiWJDmPte2NRZ8bJV" {
+		t.Errorf("P2PKH does not match. Got:%s, err:%v", addr, err)
+	}
+
+	if addr, err := w.BTCAddress(0, External, 0, P2WPKH); err != nil ||
+		addr != "bcrt1q8gk5z3dy7zv9ywe7synlrk58elz4hrnegvpv6m" {
+		t.Errorf("P2WPKH does not match. Got:%s, err:%v", addr, err)
+	}
+
+	if wif, err := w.WIF(0, External, 0); err != nil || wif != "cV6NTLu255SZ5iCNkVHezNGDH5qv6CanJpgBPqYgJU13NNKJhRs1" {
+		t.Errorf("WIF does not match. Got:%s, err:%v", wif, err)
+	}
+
+	// the watch-only wallet keeps the network
+	wo, _ := w.Neuter(0)
+	if addr, _ := wo.BTCAddress(0, External, 0, P2WPKH); addr != "bcrt1q8gk5z3dy7zv9ywe7synlrk58elz4hrnegvpv6m" {
+		t.Errorf("Watch-only P2WPKH does not match. Got:%s", addr)
+	}
+
+	// a testnet wallet restored from its xprv keeps to testnet
+	tw, _ := InitForCoin(seed, CoinTestnet)
+	xprv, _ := tw.Serialize()
+
+	r, err := Restore(xprv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr, err := r.BTCAddress(0, External, 0, P2WPKH); err != nil ||
+		addr != "tb1q8gk5z3dy7zv9ywe7synlrk58elz4hrne29cpdj" {
+		t.Errorf("Restored P2WPKH does not match. Got:%s, err:%v", addr, err)
+	}
+}
@@ -0,0 +1,168 @@
+package hd
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// DefaultCacheSize is the number of account/flg branch keys a wallet caches unless WithCacheSize is given.
+const DefaultCacheSize = 32
+
+// WithCacheSize sets the number of m/44'/coin'/wallet'/flg branch keys the wallet keeps, least recently used ones
+// being evicted first. 0 disables the cache, so each derivation derives the branch again.
+func WithCacheSize(n int) Option {
+	return func(w *HdWallet) {
+		w.cache.mu.Lock()
+		defer w.cache.mu.Unlock()
+
+		w.cache.size, w.cache.sized = n, true
+		w.cache.evict()
+	}
+}
+
+// ClearCache wipes and drops the branch keys cached by the wallet. Closing the wallet clears the cache too.
+func (w *HdWallet) ClearCache() {
+	w.cache.clear()
+}
+
+type branchKey struct {
+	wallet uint32
+	flg    uint8
+}
+
+type cacheEntry struct {
+	key     branchKey
+	k       *hdkeychain.ExtendedKey
+	refs    int  // callers deriving from k
+	evicted bool // k is wiped once refs drops to 0
+}
+
+// branchCache is a LRU cache of branch keys; its zero value holds up to DefaultCacheSize keys. Keys in use are only
+// wiped once released, so that evicting them does not break in-flight derivations.
+type branchCache struct {
+	mu    sync.Mutex
+	size  int
+	sized bool // size was set by WithCacheSize
+	ll    *list.List
+	items map[branchKey]*list.Element
+}
+
+// acquire returns the branch key for key, calling derive on a miss, and the func to call once done with it.
+func (c *branchCache) acquire(key branchKey, derive func() (*hdkeychain.ExtendedKey, error),
+) (*hdkeychain.ExtendedKey, func(), error) {
+	if e := c.get(key); e != nil {
+		return e.k, func() { c.release(e) }, nil
+	}
+
+	k, err := derive()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// memoize the public key now, as the first derivation from k would otherwise write it while others read k
+	if _, err = k.ECPubKey(); err != nil {
+		k.Zero()
+
+		return nil, nil, err
+	}
+
+	e := c.put(key, k)
+	if e == nil {
+		return k, k.Zero, nil
+	}
+
+	return e.k, func() { c.release(e) }, nil
+}
+
+func (c *branchCache) limit() int {
+	if !c.sized {
+		return DefaultCacheSize
+	}
+
+	return c.size
+}
+
+// get returns the entry of key, taking a reference on it, or nil on a miss.
+func (c *branchCache) get(key branchKey) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+
+	c.ll.MoveToFront(el)
+
+	e, _ := el.Value.(*cacheEntry)
+	e.refs++
+
+	return e
+}
+
+// put caches k for key and returns its entry with a reference taken, or nil if the cache is disabled. If another
+// caller cached key meanwhile, k is wiped and that entry is returned instead.
+func (c *branchCache) put(key branchKey, k *hdkeychain.ExtendedKey) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.limit() <= 0 {
+		return nil
+	}
+
+	if c.items == nil {
+		c.ll, c.items = list.New(), make(map[branchKey]*list.Element)
+	}
+
+	if el, ok := c.items[key]; ok {
+		k.Zero()
+
+		e, _ := el.Value.(*cacheEntry)
+		e.refs++
+
+		return e
+	}
+
+	e := &cacheEntry{key: key, k: k, refs: 1}
+	c.items[key] = c.ll.PushFront(e)
+	c.evict()
+
+	return e
+}
+
+func (c *branchCache) release(e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e.refs--; e.refs == 0 && e.evicted {
+		e.k.Zero()
+	}
+}
+
+// evict drops the least recently used entries above the limit. c.mu must be held.
+func (c *branchCache) evict() {
+	for c.ll != nil && c.ll.Len() > c.limit() && c.ll.Len() > 0 {
+		c.drop(c.ll.Back())
+	}
+}
+
+func (c *branchCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.ll != nil && c.ll.Len() > 0 {
+		c.drop(c.ll.Back())
+	}
+}
+
+// drop removes el from the cache, wiping its key unless in use. c.mu must be held.
+func (c *branchCache) drop(el *list.Element) {
+	e, _ := c.ll.Remove(el).(*cacheEntry)
+	delete(c.items, e.key)
+
+	if e.evicted = true; e.refs == 0 {
+		e.k.Zero()
+	}
+}
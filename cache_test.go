@@ -0,0 +1,141 @@
+package hd
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	w := testWallet(t, WithCacheSize(2))
+	uncached := testWallet(t, WithCacheSize(0))
+
+	for _, wallet := range []uint32{0, 1, 2, 1} {
+		addr, key, _, _ := w.Address(wallet, Change, 3)
+		if exp, expKey, _, _ := uncached.Address(wallet, Change, 3); !bytes.Equal(addr, exp) || !bytes.Equal(key, expKey) {
+			t.Errorf("Address does not match. Got:%x, expected:%x", addr, exp)
+		}
+	}
+
+	if n := len(w.cache.items); n != 2 {
+		t.Errorf("Cache size does not match. Got:%d, expected:2", n)
+	}
+
+	if _, ok := w.cache.items[branchKey{wallet: 0, flg: Change}]; ok {
+		t.Errorf("Least recently used branch was not evicted")
+	}
+
+	if n := len(uncached.cache.items); n != 0 {
+		t.Errorf("Disabled cache holds %d keys", n)
+	}
+
+	// a branch in use is only wiped once released
+	br, release, err := w.branch(1, Change)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.ClearCache()
+
+	if len(w.cache.items) != 0 || br.Depth() != 4 {
+		t.Errorf("ClearCache. Got %d keys, depth %d", len(w.cache.items), br.Depth())
+	}
+
+	release()
+
+	if br.Depth() != 0 || br.IsPrivate() {
+		t.Errorf("Released branch was not wiped")
+	}
+
+	// wallets still derive after clearing, and Close wipes the cache
+	if _, _, _, err = w.Address(1, Change, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	el := w.cache.items[branchKey{wallet: 1, flg: Change}]
+	_ = w.Close()
+
+	if e, _ := el.Value.(*cacheEntry); len(w.cache.items) != 0 || e.k.IsPrivate() {
+		t.Errorf("Close did not wipe the cache")
+	}
+}
+
+func TestConcurrentAddress(t *testing.T) {
+	// a small cache evicts branches while other goroutines derive from them
+	w := testWallet(t, WithCacheSize(2))
+	ref := testWallet(t, WithCacheSize(0))
+
+	exp := make(map[uint32][][]byte)
+
+	for wallet := uint32(0); wallet < 8; wallet++ {
+		for i := uint32(0); i < 10; i++ {
+			addr, _, _, _ := ref.Address(wallet, External, i)
+			exp[wallet] = append(exp[wallet], addr)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+
+		// even goroutines share account 0, odd ones use distinct accounts
+		wallet := uint32(0)
+		if g%2 == 1 {
+			wallet = uint32(g / 2)
+		}
+
+		go func(wallet uint32) {
+			defer wg.Done()
+
+			for i := uint32(0); i < 10; i++ {
+				addr, _, _, err := w.Address(wallet, External, i)
+				if err != nil || !bytes.Equal(addr, exp[wallet][i]) {
+					t.Errorf("Address %d/%d does not match. Got:%x, expected:%x, err:%v", wallet, i, addr,
+						exp[wallet][i], err)
+				}
+
+				if _, err = w.Addresses(wallet, External, i, 1, false); err != nil {
+					t.Errorf("Addresses %d/%d: %v", wallet, i, err)
+				}
+			}
+		}(wallet)
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkAddressConcurrent(b *testing.B) {
+	const goroutines, addresses = 16, 1000
+
+	for _, bm := range []struct {
+		name string
+		size int
+	}{{"cached", DefaultCacheSize}, {"uncached", 0}} {
+		b.Run(bm.name, func(b *testing.B) {
+			w := testWallet(b, WithCacheSize(bm.size))
+
+			for n := 0; n < b.N; n++ {
+				var wg sync.WaitGroup
+
+				for g := uint32(0); g < goroutines; g++ {
+					wg.Add(1)
+
+					go func(g uint32) {
+						defer wg.Done()
+
+						for i := g; i < addresses; i += goroutines {
+							if _, _, _, err := w.Address(g%4, External, i); err != nil {
+								b.Error(err)
+
+								return
+							}
+						}
+					}(g)
+				}
+
+				wg.Wait()
+			}
+		})
+	}
+}
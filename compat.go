@@ -0,0 +1,124 @@
+package hd
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// The methods below were promoted from the embedded *hdkeychain.ExtendedKey of earlier versions and act on the
+// m/44'/coin' branch key. The ones handing out private key material are refused while the wallet has limits.
+
+// String describes the wallet by its path and coin type. It does not print the branch key, which Serialize returns.
+func (w *HdWallet) String() string {
+	return fmt.Sprintf("hd wallet %s, coin %d", w.Path(), w.coin)
+}
+
+// IsPrivate reports whether the wallet holds the private branch key, that is it is neither watch-only nor closed.
+//
+// Deprecated: use WatchOnly.
+func (w *HdWallet) IsPrivate() bool {
+	var private bool
+
+	_ = w.withKey(false, func(k *hdkeychain.ExtendedKey) error {
+		private = k.IsPrivate()
+
+		return nil
+	})
+
+	return private
+}
+
+// ECPubKey returns the public key of the branch key.
+//
+// Deprecated: use DeriveFromPath with the Path of the wallet.
+func (w *HdWallet) ECPubKey() (pub *btcec.PublicKey, err error) {
+	err = w.withKey(false, func(k *hdkeychain.ExtendedKey) error {
+		pub, err = k.ECPubKey()
+
+		return err
+	})
+
+	return pub, err
+}
+
+// ECPrivKey returns the private key of the branch key.
+//
+// Deprecated: use DeriveFromPath with the Path of the wallet.
+func (w *HdWallet) ECPrivKey() (prv *btcec.PrivateKey, err error) {
+	err = w.withKey(true, func(k *hdkeychain.ExtendedKey) error {
+		prv, err = k.ECPrivKey()
+
+		return err
+	})
+
+	return prv, err
+}
+
+// ChainCode returns a copy of the chain code of the branch key, nil if it is not available.
+//
+// Deprecated: there is no replacement, the chain code is only needed to derive keys, see DeriveFromPath.
+func (w *HdWallet) ChainCode() []byte {
+	var code []byte
+
+	_ = w.withKey(true, func(k *hdkeychain.ExtendedKey) error {
+		code = append(code, k.ChainCode()...)
+
+		return nil
+	})
+
+	return code
+}
+
+// ChildIndex returns the index of the branch key, hardened coin type, or 0 if it is not available.
+//
+// Deprecated: use Coin.
+func (w *HdWallet) ChildIndex() uint32 {
+	var index uint32
+
+	_ = w.withKey(false, func(k *hdkeychain.ExtendedKey) error {
+		index = k.ChildIndex()
+
+		return nil
+	})
+
+	return index
+}
+
+// IsForNet reports whether the branch key is serialized for net.
+//
+// Deprecated: use Network.
+func (w *HdWallet) IsForNet(net *chaincfg.Params) bool {
+	var ok bool
+
+	_ = w.withKey(false, func(k *hdkeychain.ExtendedKey) error {
+		ok = k.IsForNet(net)
+
+		return nil
+	})
+
+	return ok
+}
+
+// withKey calls fn with the branch key under the read lock. Watch-only and closed wallets report ErrWatchOnly and
+// ErrClosed, and private, when fn hands out private key material, refuses it while the wallet has limits.
+func (w *HdWallet) withKey(private bool, fn func(k *hdkeychain.ExtendedKey) error) error {
+	if w.WatchOnly() {
+		return ErrWatchOnly
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	if private && w.policy.limited() {
+		return fmt.Errorf("%w: branch key of a wallet with limits", ErrPolicyExceeded)
+	}
+
+	return fn(w.key)
+}
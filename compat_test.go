@@ -0,0 +1,62 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestDeprecatedKeyMethods(t *testing.T) {
+	w := testWallet(t)
+
+	branch, err := w.DeriveFromPath(Path{hardened + 44, hardened + 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// printing the wallet does not print its key
+	if xprv, _ := w.Serialize(); w.String() != "hd wallet m/44'/60', coin 60" || strings.Contains(fmt.Sprint(w), xprv) {
+		t.Errorf("String does not match. Got:%s", w.String())
+	}
+
+	pub, err := w.ECPubKey()
+	if exp, _ := branch.ECPubKey(); err != nil || !pub.IsEqual(exp) {
+		t.Errorf("ECPubKey does not match, err:%v", err)
+	}
+
+	prv, err := w.ECPrivKey()
+	if exp, _ := branch.ECPrivKey(); err != nil || !bytes.Equal(prv.Serialize(), exp.Serialize()) {
+		t.Errorf("ECPrivKey does not match, err:%v", err)
+	}
+
+	if !w.IsPrivate() || w.ChildIndex() != hardened+60 || !bytes.Equal(w.ChainCode(), branch.ChainCode()) ||
+		!w.IsForNet(&chaincfg.MainNetParams) || w.IsForNet(&chaincfg.TestNet3Params) {
+		t.Errorf("Branch key does not match")
+	}
+
+	// private key material is refused while the wallet has limits
+	w.SetLimits(Limits{MaxIndex: 10})
+
+	if _, err = w.ECPrivKey(); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("ECPrivKey. Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	if w.ChainCode() != nil || !w.IsPrivate() {
+		t.Errorf("Branch key of a wallet with limits. Got:%x", w.ChainCode())
+	}
+
+	wo, _ := testWallet(t).Neuter(0)
+	if _, err = wo.ECPubKey(); !errors.Is(err, ErrWatchOnly) || wo.IsPrivate() {
+		t.Errorf("Watch-only. Expected ErrWatchOnly, got %v", err)
+	}
+
+	_ = w.Close()
+
+	if _, err = w.ECPubKey(); !errors.Is(err, ErrClosed) || w.IsPrivate() || w.ChildIndex() != 0 {
+		t.Errorf("Closed. Expected ErrClosed, got %v", err)
+	}
+}
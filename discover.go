@@ -0,0 +1,85 @@
+package hd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// DefaultGapLimit is the gap of unused addresses after which BIP44 account discovery stops.
+const DefaultGapLimit = 20
+
+// DiscoveredAddress is a used address found by Discover.
+type DiscoveredAddress struct {
+	Index   uint32
+	Address []byte
+}
+
+// Discover scans the BIP44 addresses m/44'/coin'/wallet'/flg/i (see PublicAddress) in order, asking used whether each
+// one has been used, and stops after gapLimit consecutive unused addresses, DefaultGapLimit if 0. It returns the used
+// addresses found, none if the first gapLimit addresses are unused. It works on watch-only wallets.
+// Errors of used are returned with the index attached, along with the addresses found so far, and so is the error of
+// ctx if it is done before the scan ends.
+func (w *HdWallet) Discover(ctx context.Context, wallet uint32, flg uint8, used func(addr []byte) (bool, error),
+	gapLimit uint32,
+) ([]DiscoveredAddress, error) {
+	if gapLimit == 0 {
+		gapLimit = DefaultGapLimit
+	}
+
+	br, release, err := w.branch(wallet, flg)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	found := []DiscoveredAddress{}
+
+	for i, gap := uint32(0), uint32(0); gap < gapLimit; i++ {
+		if i >= hardened {
+			return found, fmt.Errorf("hd: address %d: %w", i, ErrIndexOutOfRange)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return found, fmt.Errorf("hd: address %d: %w", i, err)
+		}
+
+		addr, err := w.publicLeaf(br, wallet, i)
+		if err != nil {
+			return found, fmt.Errorf("hd: address %d: %w", i, err)
+		}
+
+		ok, err := used(addr)
+		if err != nil {
+			return found, fmt.Errorf("hd: address %d: %w", i, err)
+		}
+
+		if !ok {
+			gap++
+
+			continue
+		}
+
+		found = append(found, DiscoveredAddress{Index: i, Address: addr})
+		gap = 0
+	}
+
+	return found, nil
+}
+
+// publicLeaf returns the address of the non-hardened child addrNum of br.
+func (w *HdWallet) publicLeaf(br *hdkeychain.ExtendedKey, wallet, addrNum uint32) ([]byte, error) {
+	k, err := w.leaf(br, wallet, addrNum, addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Zero()
+
+	pub, err := k.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return w.encodeAddress(pub), nil
+}
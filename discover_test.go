@@ -0,0 +1,103 @@
+package hd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	w := testWallet(t)
+
+	tests := []struct {
+		used []uint32
+		gap  uint32
+		exp  []uint32
+	}{
+		{nil, 0, []uint32{}},
+		{[]uint32{0}, 0, []uint32{0}},
+		{[]uint32{0, 5, 24}, 0, []uint32{0, 5, 24}},
+		{[]uint32{0, 21}, 0, []uint32{0}}, // 20 unused addresses before 21
+		{[]uint32{19}, 0, []uint32{19}},
+		{[]uint32{20}, 0, []uint32{}},
+		{[]uint32{1, 3, 6}, 2, []uint32{1, 3}},
+	}
+
+	for i, tt := range tests {
+		usedAddrs := make([][]byte, 0, len(tt.used))
+
+		for _, n := range tt.used {
+			addr, _ := w.PublicAddress(2, External, n)
+			usedAddrs = append(usedAddrs, addr)
+		}
+
+		found, err := w.Discover(context.Background(), 2, External, func(addr []byte) (bool, error) {
+			for _, u := range usedAddrs {
+				if bytes.Equal(u, addr) {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		}, tt.gap)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if found == nil || len(found) != len(tt.exp) {
+			t.Errorf("%d: Got:%v, expected:%v", i, found, tt.exp)
+
+			continue
+		}
+
+		for j, f := range found {
+			if exp, _ := w.PublicAddress(2, External, tt.exp[j]); f.Index != tt.exp[j] || !bytes.Equal(f.Address, exp) {
+				t.Errorf("%d: Got:%d %x, expected:%d %x", i, f.Index, f.Address, tt.exp[j], exp)
+			}
+		}
+	}
+}
+
+func TestDiscoverErrors(t *testing.T) {
+	w := testWallet(t)
+	errRPC := errors.New("rpc failed")
+
+	n := 0
+	found, err := w.Discover(context.Background(), 2, External, func([]byte) (bool, error) {
+		if n++; n == 4 {
+			return false, errRPC
+		}
+
+		return true, nil
+	}, 0)
+
+	if !errors.Is(err, errRPC) || !strings.Contains(err.Error(), "address 3") || len(found) != 3 {
+		t.Errorf("Expected the callback error at address 3, got %v and %d addresses", err, len(found))
+	}
+
+	// cancelling stops the scan
+	ctx, cancel := context.WithCancel(context.Background())
+	n = 0
+
+	found, err = w.Discover(ctx, 2, External, func([]byte) (bool, error) {
+		if n++; n == 5 {
+			cancel()
+		}
+
+		return true, nil
+	}, 0)
+
+	if !errors.Is(err, context.Canceled) || len(found) != 5 {
+		t.Errorf("Expected context.Canceled, got %v and %d addresses", err, len(found))
+	}
+
+	// watch-only wallets discover too
+	wo, _ := w.Neuter(2)
+	if found, err = wo.Discover(context.Background(), 2, External, func([]byte) (bool, error) {
+		return false, nil
+	}, 0); err != nil || len(found) != 0 {
+		t.Errorf("Watch-only Discover. Got:%v, err:%v", found, err)
+	}
+}
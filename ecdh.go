@@ -0,0 +1,85 @@
+package hd
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/hkdf"
+)
+
+// ErrPointNotOnCurve will be reported for well-formed public keys whose point is not on secp256k1.
+var ErrPointNotOnCurve error = errors.New("hd: public key is not on the curve")
+
+// KDF turns the x coordinate of an ECDH shared point into the secret returned by SharedSecret.
+type KDF func(x []byte) ([]byte, error)
+
+// RawKDF returns the shared x coordinate unchanged.
+func RawKDF(x []byte) ([]byte, error) {
+	return append([]byte(nil), x...), nil
+}
+
+// SHA256KDF returns the SHA-256 of the shared x coordinate.
+func SHA256KDF(x []byte) ([]byte, error) {
+	h := sha256.Sum256(x)
+
+	return h[:], nil
+}
+
+// HKDFSHA256 returns a KDF expanding the shared x coordinate into length bytes with HKDF-SHA256 (RFC 5869).
+func HKDFSHA256(salt, info []byte, length int) KDF {
+	return func(x []byte) ([]byte, error) {
+		out := make([]byte, length)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, x, salt, info), out); err != nil {
+			return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+		}
+
+		return out, nil
+	}
+}
+
+// SharedSecret computes the ECDH shared secret between the key of the given wallet, flg and address number and
+// peerPub, a compressed or uncompressed secp256k1 public key, and passes its x coordinate through kdf (RawKDF if nil).
+func (w *HdWallet) SharedSecret(wallet uint32, flg uint8, addrNum uint32, peerPub []byte, kdf KDF) ([]byte, error) {
+	pub, err := parsePubKey(peerPub)
+	if err != nil {
+		return nil, err
+	}
+
+	_, key, _, err := w.Address(wallet, flg, addrNum)
+	if err != nil {
+		return nil, err
+	}
+
+	prv, _ := btcec.PrivKeyFromBytes(key)
+	defer prv.Zero()
+
+	zero(key)
+
+	x := btcec.GenerateSharedSecret(prv, pub)
+	defer zero(x)
+
+	if kdf == nil {
+		kdf = RawKDF
+	}
+
+	return kdf(x)
+}
+
+// parsePubKey parses a SEC1 encoded secp256k1 public key, telling malformed encodings from points off the curve. The
+// point at infinity has no valid encoding, so it is rejected as malformed.
+func parsePubKey(pub []byte) (*btcec.PublicKey, error) {
+	key, err := btcec.ParsePubKey(pub)
+	if errors.Is(err, secp.ErrPubKeyNotOnCurve) {
+		return nil, ErrPointNotOnCurve
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPublicKey, err.Error())
+	}
+
+	return key, nil
+}
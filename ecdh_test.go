@@ -0,0 +1,92 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+func TestSharedSecret(t *testing.T) {
+	w := testWallet(t)
+
+	_, _, prvA, _ := w.Address(2, External, 0)
+	_, _, prvB, _ := w.Address(2, Change, 1)
+	pubA, _, _ := w.PublicKey(2, External, 0)
+	_, pubB, _ := w.PublicKey(2, Change, 1)
+
+	// the raw secret is the x coordinate of keyA * pubB
+	x, _ := btcec.S256().ScalarMult(prvA.X, prvA.Y, prvB.D.Bytes())
+	xExp := x.FillBytes(make([]byte, 32))
+
+	sum := SHA256KDF
+
+	tests := []struct {
+		name string
+		kdf  KDF
+		exp  []byte // nil when only symmetry is checked
+	}{
+		{"nil", nil, xExp},
+		{"raw", RawKDF, xExp},
+		{"sha256", sum, nil},
+		{"hkdf", HKDFSHA256([]byte("salt"), []byte("info"), 64), nil},
+	}
+	tests[2].exp, _ = sum(xExp)
+
+	for _, tt := range tests {
+		ab, err := w.SharedSecret(2, External, 0, pubB, tt.kdf)
+		if err != nil {
+			t.Fatalf("%s: SharedSecret %e", tt.name, err)
+		}
+
+		ba, err := w.SharedSecret(2, Change, 1, pubA, tt.kdf)
+		if err != nil {
+			t.Fatalf("%s: SharedSecret %e", tt.name, err)
+		}
+
+		if !bytes.Equal(ab, ba) {
+			t.Errorf("%s: secrets are not symmetric. Got:%x and %x", tt.name, ab, ba)
+		}
+
+		if tt.exp != nil && !bytes.Equal(ab, tt.exp) {
+			t.Errorf("%s: secret does not match. Got:%x, expected:%x", tt.name, ab, tt.exp)
+		}
+	}
+}
+
+func TestHKDFSHA256(t *testing.T) {
+	// RFC 5869 test case 1
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	okm := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+
+	if out, err := HKDFSHA256(salt, info, 42)(ikm); err != nil || hex.EncodeToString(out) != okm {
+		t.Errorf("OKM does not match. Got:%x, err:%v", out, err)
+	}
+}
+
+func TestSharedSecretInvalidPeer(t *testing.T) {
+	w := testWallet(t)
+
+	offCurve := make([]byte, 65)
+	offCurve[0], offCurve[32], offCurve[64] = 0x04, 0x01, 0x01
+
+	tests := []struct {
+		pub []byte
+		err error
+	}{
+		{nil, ErrInvalidPublicKey},
+		{[]byte{0x00}, ErrInvalidPublicKey}, // point at infinity
+		{offCurve[:33], ErrInvalidPublicKey},
+		{offCurve, ErrPointNotOnCurve},
+	}
+
+	for i, tt := range tests {
+		if _, err := w.SharedSecret(2, External, 0, tt.pub, nil); !errors.Is(err, tt.err) {
+			t.Errorf("%d: expected %v, got %v", i, tt.err, err)
+		}
+	}
+}
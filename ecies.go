@@ -0,0 +1,128 @@
+package hd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// ECIES parameters as used by go-ethereum's ecies package for secp256k1 (ECIES_AES128_SHA256).
+const (
+	eciesKeyLen = 16                                     // AES-128 key and HMAC key material
+	eciesPubLen = 65                                     // uncompressed ephemeral public key
+	eciesIVLen  = aes.BlockSize                          // CTR initialization vector
+	eciesMinLen = eciesPubLen + eciesIVLen + sha256.Size // ciphertext of an empty message
+)
+
+var (
+	// ErrInvalidPublicKey will be reported for public keys that cannot be parsed or are not on the curve.
+	ErrInvalidPublicKey error = errors.New("hd: invalid public key")
+	// ErrInvalidCiphertext will be reported when a ciphertext is malformed or fails authentication.
+	ErrInvalidCiphertext error = errors.New("hd: invalid ciphertext")
+)
+
+// Encrypt encrypts plaintext to the secp256k1 public key pub, given in compressed or uncompressed form, so that only
+// the holder of the private key can decrypt it. The ciphertext interoperates with go-ethereum's ecies package: an
+// ephemeral public key, an AES-128-CTR encrypted message with its IV, and an HMAC-SHA-256 tag.
+func Encrypt(pub, plaintext []byte) ([]byte, error) {
+	pubKey, err := parsePubKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+	defer ephemeral.Zero()
+
+	ke, km := eciesKeys(btcec.GenerateSharedSecret(ephemeral, pubKey))
+
+	ct := make([]byte, eciesPubLen+eciesIVLen+len(plaintext), eciesMinLen+len(plaintext))
+	copy(ct, ephemeral.PubKey().SerializeUncompressed())
+
+	iv := ct[eciesPubLen : eciesPubLen+eciesIVLen]
+	if _, err = rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	block, err := aes.NewCipher(ke)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	cipher.NewCTR(block, iv).XORKeyStream(ct[eciesPubLen+eciesIVLen:], plaintext)
+
+	return append(ct, eciesTag(km, ct[eciesPubLen:])...), nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt (or go-ethereum's ecies) for the public key of the given wallet,
+// flg and address number. The tag is verified before anything is decrypted.
+func (w *HdWallet) Decrypt(wallet uint32, flg uint8, addrNum uint32, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < eciesMinLen || ciphertext[0] != 0x04 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	ephemeral, err := btcec.ParsePubKey(ciphertext[:eciesPubLen])
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	_, key, _, err := w.Address(wallet, flg, addrNum)
+	if err != nil {
+		return nil, err
+	}
+
+	prv, _ := btcec.PrivKeyFromBytes(key)
+	defer prv.Zero()
+
+	zero(key)
+
+	ke, km := eciesKeys(btcec.GenerateSharedSecret(prv, ephemeral))
+
+	em := ciphertext[eciesPubLen : len(ciphertext)-sha256.Size]
+	if !hmac.Equal(eciesTag(km, em), ciphertext[len(ciphertext)-sha256.Size:]) {
+		return nil, ErrInvalidCiphertext
+	}
+
+	block, err := aes.NewCipher(ke)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	plaintext := make([]byte, len(em)-eciesIVLen)
+	cipher.NewCTR(block, em[:eciesIVLen]).XORKeyStream(plaintext, em[eciesIVLen:])
+
+	return plaintext, nil
+}
+
+// eciesKeys derives the encryption and MAC keys from the shared secret z with the NIST SP 800-56 concatenation KDF.
+func eciesKeys(z []byte) (ke, km []byte) {
+	var counter [4]byte
+
+	binary.BigEndian.PutUint32(counter[:], 1)
+
+	h := sha256.New()
+	h.Write(counter[:])
+	h.Write(z)
+	k := h.Sum(nil) // a single round yields the 2*eciesKeyLen bytes needed
+
+	mk := sha256.Sum256(k[eciesKeyLen : 2*eciesKeyLen])
+
+	return k[:eciesKeyLen], mk[:]
+}
+
+// eciesTag authenticates the IV and encrypted message.
+func eciesTag(km, em []byte) []byte {
+	mac := hmac.New(sha256.New, km)
+	mac.Write(em)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,63 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestECIES(t *testing.T) {
+	w := testWallet(t)
+	msg := []byte("customer KYC blob")
+
+	comp, uncomp, err := w.PublicKey(2, External, 1)
+	if err != nil {
+		t.Fatalf("PublicKey %e", err)
+	}
+
+	for _, pub := range [][]byte{uncomp, comp} {
+		ct, err := Encrypt(pub, msg)
+		if err != nil {
+			t.Fatalf("Encrypt %e", err)
+		}
+
+		pt, err := w.Decrypt(2, External, 1, ct)
+		if err != nil || !bytes.Equal(pt, msg) {
+			t.Errorf("Decrypt does not match. Got:%q, err:%v", pt, err)
+		}
+
+		// the wrong key fails authentication
+		if _, err = w.Decrypt(2, External, 2, ct); !errors.Is(err, ErrInvalidCiphertext) {
+			t.Errorf("Decrypt with wrong key. Expected ErrInvalidCiphertext, got %v", err)
+		}
+	}
+
+	if _, err = Encrypt([]byte{0x02, 0x01}, msg); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("Expected ErrInvalidPublicKey, got %v", err)
+	}
+}
+
+func TestECIESTampered(t *testing.T) {
+	w := testWallet(t)
+
+	_, pub, _ := w.PublicKey(2, External, 1)
+
+	ct, err := Encrypt(pub, []byte("tamper"))
+	if err != nil {
+		t.Fatalf("Encrypt %e", err)
+	}
+
+	for i := range ct {
+		ct[i] ^= 0x01
+
+		if pt, err := w.Decrypt(2, External, 1, ct); !errors.Is(err, ErrInvalidCiphertext) || pt != nil {
+			t.Errorf("Byte %d flipped. Expected ErrInvalidCiphertext, got %q, %v", i, pt, err)
+		}
+
+		ct[i] ^= 0x01
+	}
+
+	if _, err = w.Decrypt(2, External, 1, ct[:eciesMinLen-1]); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Truncated ciphertext. Expected ErrInvalidCiphertext, got %v", err)
+	}
+}
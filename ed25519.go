@@ -0,0 +1,105 @@
+package hd
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotHardened will be reported for non-hardened path components, which SLIP-10 ed25519 derivation does not
+// support.
+var ErrNotHardened error = errors.New("hd: ed25519 derivation only supports hardened indices")
+
+// Ed25519Wallet derives ed25519 keys, as used by Solana, Stellar or Cardano, from a seed following SLIP-10. It is
+// safe for concurrent use.
+type Ed25519Wallet struct {
+	key, chainCode []byte // master node
+	mu             sync.RWMutex
+	closed         bool
+}
+
+// InitSLIP10Ed25519 generates the SLIP-10 ed25519 master node of the seed, which must be 16 to 64 bytes long.
+func InitSLIP10Ed25519(seed []byte) (*Ed25519Wallet, error) {
+	key, chainCode, err := masterSecret(seed, masterKeyEd25519)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ed25519Wallet{key: key, chainCode: chainCode}, nil
+}
+
+// Key derives the key pair at path, e.g. "m/44'/501'/0'/0'". Every component must be hardened, else ErrNotHardened
+// is returned. "m" returns the key of the master node.
+func (w *Ed25519Wallet) Key(path string) (pub ed25519.PublicKey, prv ed25519.PrivateKey, err error) {
+	var p Path
+
+	if path != "m" {
+		if p, err = ParsePath(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i, n := range p {
+		if n < hardened {
+			return nil, nil, fmt.Errorf("%w: component %d of %s", ErrNotHardened, i, p)
+		}
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil, nil, ErrClosed
+	}
+
+	key := append([]byte(nil), w.key...)
+	chainCode := append([]byte(nil), w.chainCode...)
+
+	for _, n := range p {
+		key, chainCode = ed25519Child(key, chainCode, n)
+	}
+
+	zero(chainCode)
+
+	prv = ed25519.NewKeyFromSeed(key)
+	zero(key)
+
+	pub, _ = prv.Public().(ed25519.PublicKey)
+
+	return pub, prv, nil
+}
+
+// Close wipes the master node. Any later call to Key returns ErrClosed.
+func (w *Ed25519Wallet) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+
+	zero(w.key)
+	zero(w.chainCode)
+
+	return nil
+}
+
+// ed25519Child derives the hardened child index of the node key, chainCode, wiping them.
+func ed25519Child(key, chainCode []byte, index uint32) (childKey, childChainCode []byte) {
+	//   I = HMAC-SHA512(Key = chainCode, Data = 0x00 || key || ser32(index))
+	data := make([]byte, 1+len(key)+4)
+	copy(data[1:], key)
+	binary.BigEndian.PutUint32(data[1+len(key):], index)
+
+	hmac512 := hmac.New(sha512.New, chainCode)
+	_, _ = hmac512.Write(data)
+	lr := hmac512.Sum(nil)
+
+	zero(data)
+	zero(key)
+	zero(chainCode)
+
+	return lr[:len(lr)/2], lr[len(lr)/2:]
+}
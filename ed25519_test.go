@@ -0,0 +1,93 @@
+package hd
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// slip10Seed2 is the seed of the second SLIP-10 test vector.
+const slip10Seed2 = "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542" //nolint:lll // seed literal is 128 digits
+
+func TestEd25519Wallet(t *testing.T) {
+	// SLIP-10 test vectors for ed25519, the public keys without their 0x00 prefix
+	tests := []struct {
+		seed, path, prv, pub string
+	}{
+		{"000102030405060708090a0b0c0d0e0f", "m",
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0H",
+			"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			"8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0H/1H",
+			"b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			"1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0H/1H/2H",
+			"92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+			"ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0H/1H/2H/2H",
+			"30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+			"8abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c"},
+		{"000102030405060708090a0b0c0d0e0f", "m/0H/1H/2H/2H/1000000000H",
+			"8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+			"3c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a"},
+		{slip10Seed2, "m",
+			"171cb88b1b3c1db25add599712e36245d75bc65a1a5c9e18d76f9f2b1eab4012",
+			"8fe9693f8fa62a4305a140b9764c5ee01e455963744fe18204b4fb948249308a"},
+		{slip10Seed2, "m/0H",
+			"1559eb2bbec5790b0c65d8693e4d0875b1747f4970ae8b650486ed7470845635",
+			"86fab68dcb57aa196c77c5f264f215a112c22a912c10d123b0d03c3c28ef1037"},
+		{slip10Seed2, "m/0H/2147483647H",
+			"ea4f5bfe8694d8bb74b7b59404632fd5968b774ed545e810de9c32a4fb4192f4",
+			"5ba3b9ac6e90e83effcd25ac4e58a1365a9e35a3d3ae5eb07b9e4d90bcf7506d"},
+		{slip10Seed2, "m/0H/2147483647H/1H",
+			"3757c7577170179c7868353ada796c839135b3d30554bbb74a4b1e4a5a58505c",
+			"2e66aa57069c86cc18249aecf5cb5a9cebbfd6fadeab056254763874a9352b45"},
+		{slip10Seed2, "m/0H/2147483647H/1H/2147483646H",
+			"5837736c89570de861ebc173b1086da4f505d4adb387c6a1b1342d5e4ac9ec72",
+			"e33c0f7d81d843c572275f287498e8d408654fdf0d1e065b84e2e6f157aab09b"},
+		{slip10Seed2, "m/0H/2147483647H/1H/2147483646H/2H",
+			"551d333177df541ad876a60ea71f00447931c0a9da16f227c11ea080d7391b8d",
+			"47150c75db263559a70d5778bf36abbab30fb061ad69f69ece61a72b0cfa4fc0"},
+	}
+
+	for _, tt := range tests {
+		seed, _ := hex.DecodeString(tt.seed)
+
+		w, err := InitSLIP10Ed25519(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pub, prv, err := w.Key(tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+
+		if hex.EncodeToString(prv.Seed()) != tt.prv || hex.EncodeToString(pub) != tt.pub {
+			t.Errorf("%s: Got:%x %x, expected:%s %s", tt.path, prv.Seed(), pub, tt.prv, tt.pub)
+		}
+	}
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	w, _ := InitSLIP10Ed25519(seed)
+
+	if _, _, err := w.Key("m/44'/501'/0'/0"); !errors.Is(err, ErrNotHardened) {
+		t.Errorf("Expected ErrNotHardened, got %v", err)
+	}
+
+	if _, _, err := w.Key("m/x"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, got %v", err)
+	}
+
+	if _, err := InitSLIP10Ed25519(seed[:15]); !errors.Is(err, ErrInvalidSeedLen) {
+		t.Errorf("Expected ErrInvalidSeedLen, got %v", err)
+	}
+
+	_ = w.Close()
+
+	if _, _, err := w.Key("m/0'"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package hd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Wallet file header: magic, version, scrypt N and P, salt, GCM nonce, password check and the SHA-256 of all of them.
+const (
+	walletFileMagic   = "HDWF"
+	walletFileVersion = 1
+	hdrScrypt         = len(walletFileMagic) + 1
+	hdrSalt           = hdrScrypt + 8
+	hdrNonce          = hdrSalt + 32
+	hdrCheck          = hdrNonce + 12
+	hdrDigest         = hdrCheck + sha256.Size
+	walletFileHdrLen  = hdrDigest + sha256.Size
+	maxScryptN        = 1 << 20 // cap on the scrypt N of a file, so corrupted headers cannot exhaust memory
+	maxScryptP        = 1 << 8
+)
+
+// ErrCorruptedFile will be reported for wallet files that are truncated, altered or not written by SaveEncrypted.
+var ErrCorruptedFile error = errors.New("hd: corrupted wallet file")
+
+// SaveEncrypted writes the branch key of the wallet, as returned by Serialize, to the file at path encrypted with
+// password: scrypt KDF and AES-256-GCM, after a versioned header. The scrypt parameters default to StandardScryptN
+// and StandardScryptP and can be set with WithScryptParams. The file is created with mode 0600.
+func (w *HdWallet) SaveEncrypted(path, password string, opts ...KeystoreOption) error {
+	kp := keystoreParams{n: StandardScryptN, p: StandardScryptP}
+	for _, opt := range opts {
+		opt(&kp)
+	}
+
+	if kp.n < 2 || kp.n&(kp.n-1) != 0 || kp.n > maxScryptN || kp.p < 1 || kp.p > maxScryptP {
+		return fmt.Errorf("%w: scrypt parameters out of range", ErrInvalidKeystore)
+	}
+
+	xprv, err := w.Serialize()
+	if err != nil {
+		return err
+	}
+
+	plain := []byte(xprv)
+	defer zero(plain)
+
+	hdr := make([]byte, walletFileHdrLen)
+	copy(hdr, walletFileMagic)
+	hdr[hdrScrypt-1] = walletFileVersion
+	binary.BigEndian.PutUint32(hdr[hdrScrypt:], uint32(kp.n))
+	binary.BigEndian.PutUint32(hdr[hdrScrypt+4:], uint32(kp.p))
+
+	// random salt and nonce
+	if _, err = rand.Read(hdr[hdrSalt:hdrCheck]); err != nil {
+		return fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	aead, check, err := walletFileCipher(password, hdr)
+	if err != nil {
+		return err
+	}
+
+	copy(hdr[hdrCheck:], check)
+
+	digest := sha256.Sum256(hdr[:hdrDigest])
+	copy(hdr[hdrDigest:], digest[:])
+
+	// the header is authenticated as additional data
+	return os.WriteFile(path, aead.Seal(hdr, hdr[hdrNonce:hdrCheck], plain, hdr), 0o600)
+}
+
+// LoadEncrypted reads a wallet file written by SaveEncrypted and restores the wallet with opts, see Restore. A wrong
+// password reports ErrInvalidPassword, and a truncated or altered file ErrCorruptedFile. The header is checked against
+// its digest before the password, so that an altered header is not mistaken for a wrong password.
+func LoadEncrypted(path, password string, opts ...Option) (*HdWallet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < walletFileHdrLen || !bytes.Equal(data[:len(walletFileMagic)], []byte(walletFileMagic)) {
+		return nil, fmt.Errorf("%w: missing header", ErrCorruptedFile)
+	}
+
+	if v := data[hdrScrypt-1]; v != walletFileVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrCorruptedFile, v)
+	}
+
+	if digest := sha256.Sum256(data[:hdrDigest]); !hmac.Equal(digest[:], data[hdrDigest:walletFileHdrLen]) {
+		return nil, fmt.Errorf("%w: header digest does not match", ErrCorruptedFile)
+	}
+
+	if n, p := binary.BigEndian.Uint32(data[hdrScrypt:]), binary.BigEndian.Uint32(data[hdrScrypt+4:]); n > maxScryptN ||
+		p > maxScryptP {
+		return nil, fmt.Errorf("%w: scrypt parameters out of range", ErrCorruptedFile)
+	}
+
+	hdr, sealed := data[:walletFileHdrLen], data[walletFileHdrLen:]
+
+	aead, check, err := walletFileCipher(password, hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal(check, hdr[hdrCheck:hdrDigest]) {
+		return nil, ErrInvalidPassword
+	}
+
+	plain, err := aead.Open(nil, hdr[hdrNonce:hdrCheck], sealed, hdr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCorruptedFile, err.Error())
+	}
+	defer zero(plain)
+
+	return Restore(string(plain), opts...)
+}
+
+// walletFileCipher derives the AES-256-GCM cipher and the password check of a wallet file from password and the
+// scrypt parameters and salt in hdr.
+func walletFileCipher(password string, hdr []byte) (cipher.AEAD, []byte, error) {
+	n, p := binary.BigEndian.Uint32(hdr[hdrScrypt:]), binary.BigEndian.Uint32(hdr[hdrScrypt+4:])
+
+	derived, err := scrypt.Key([]byte(password), hdr[hdrSalt:hdrNonce], int(n), scryptR, int(p), 2*scryptDKLen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrCorruptedFile, err.Error())
+	}
+	defer zero(derived)
+
+	block, err := aes.NewCipher(derived[:scryptDKLen])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	check := sha256.Sum256(derived[scryptDKLen:])
+
+	return aead, check[:], nil
+}
@@ -0,0 +1,90 @@
+//go:build !(js && wasm)
+
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveEncrypted(t *testing.T) {
+	w := testWallet(t)
+	path := filepath.Join(t.TempDir(), "wallet.hdw")
+
+	if err := w.SaveEncrypted(path, "secret", WithScryptParams(LightScryptN, LightScryptP)); err != nil {
+		t.Fatal(err)
+	}
+
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("Got mode %v, expected 0600, err:%v", fi.Mode(), err)
+	}
+
+	r, err := LoadEncrypted(path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := uint32(0); i < 3; i++ {
+		exp, _, _, _ := w.Address(2, External, i)
+		if addr, _, _, _ := r.Address(2, External, i); !bytes.Equal(addr, exp) {
+			t.Errorf("Address does not match. Got:%x, expected:%x", addr, exp)
+		}
+	}
+
+	if r, err = LoadEncrypted(path, "Secret"); !errors.Is(err, ErrInvalidPassword) || r != nil {
+		t.Errorf("Expected ErrInvalidPassword, got %v", err)
+	}
+
+	wo, _ := w.Neuter(2)
+	if err = wo.SaveEncrypted(path, "secret"); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+
+	if err = w.SaveEncrypted(path, "secret", WithScryptParams(1000, 1)); !errors.Is(err, ErrInvalidKeystore) {
+		t.Errorf("Expected ErrInvalidKeystore, got %v", err)
+	}
+}
+
+func TestLoadEncryptedCorrupted(t *testing.T) {
+	w := testWallet(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wallet.hdw")
+
+	if err := w.SaveEncrypted(path, "secret", WithScryptParams(LightScryptN, LightScryptP)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+
+	tests := []struct {
+		name   string
+		tamper func([]byte) []byte
+		err    error
+	}{
+		{"ciphertext", func(b []byte) []byte { b[walletFileHdrLen+3] ^= 0x01; return b }, ErrCorruptedFile},
+		{"tag", func(b []byte) []byte { b[len(b)-1] ^= 0x80; return b }, ErrCorruptedFile},
+		{"nonce", func(b []byte) []byte { b[hdrNonce] ^= 0x01; return b }, ErrCorruptedFile},
+		{"truncated", func(b []byte) []byte { return b[:len(b)-10] }, ErrCorruptedFile},
+		{"header only", func(b []byte) []byte { return b[:walletFileHdrLen-1] }, ErrCorruptedFile},
+		{"magic", func(b []byte) []byte { b[0] = 'X'; return b }, ErrCorruptedFile},
+		{"version", func(b []byte) []byte { b[hdrScrypt-1] = 9; return b }, ErrCorruptedFile},
+		{"scrypt", func(b []byte) []byte { b[hdrScrypt] = 0xff; return b }, ErrCorruptedFile},
+		{"salt", func(b []byte) []byte { b[hdrSalt] ^= 0x01; return b }, ErrCorruptedFile},
+		{"check", func(b []byte) []byte { b[hdrCheck+5] ^= 0x01; return b }, ErrCorruptedFile},
+		{"digest", func(b []byte) []byte { b[hdrDigest] ^= 0x01; return b }, ErrCorruptedFile},
+	}
+
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(p, tt.tamper(append([]byte(nil), data...)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if r, err := LoadEncrypted(p, "secret"); !errors.Is(err, tt.err) || r != nil {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
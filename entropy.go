@@ -0,0 +1,150 @@
+package hd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidEntropyLen will be reported for entropy shares that are not 32 or 64 bytes long.
+	ErrInvalidEntropyLen error = errors.New("hd: length of entropy is invalid")
+	// ErrNoShares will be reported when combining an empty set of shares.
+	ErrNoShares error = errors.New("hd: no entropy shares")
+	// ErrShareMismatch will be reported when shares and commitments do not pair up or have different lengths.
+	ErrShareMismatch error = errors.New("hd: entropy shares do not match")
+	// ErrDuplicateShare will be reported when two participants commit to the same share, which would cancel out.
+	ErrDuplicateShare error = errors.New("hd: duplicate entropy share")
+	// ErrCommitmentMismatch is wrapped by CommitmentError.
+	ErrCommitmentMismatch error = errors.New("hd: share does not match its commitment")
+)
+
+// CommitmentError identifies the participants whose revealed share does not match the commitment they published.
+type CommitmentError struct {
+	Participants []int // indices into the shares given to CombineEntropy
+}
+
+func (e *CommitmentError) Error() string {
+	return fmt.Sprintf("%s: participants %v", ErrCommitmentMismatch, e.Participants)
+}
+
+// Unwrap allows errors.Is(err, ErrCommitmentMismatch).
+func (e *CommitmentError) Unwrap() error {
+	return ErrCommitmentMismatch
+}
+
+// ContributeEntropy generates a participant's 32-byte share and the commitment to publish before any share is
+// revealed. It panics if the system random source fails, rather than hand out a share that is not random; use
+// ContributeEntropySize to get an error instead, or for 64-byte shares.
+func ContributeEntropy() (share, commitment []byte) {
+	share, commitment, err := contributeEntropy(rand.Reader, sha256.Size)
+	if err != nil {
+		panic(err)
+	}
+
+	return share, commitment
+}
+
+// ContributeEntropySize generates a participant's share of size bytes (32 or 64) and the commitment to publish
+// before any share is revealed.
+func ContributeEntropySize(size int) (share, commitment []byte, err error) {
+	return contributeEntropy(rand.Reader, size)
+}
+
+// contributeEntropy generates a share of size bytes read from r, and its commitment.
+func contributeEntropy(r io.Reader, size int) (share, commitment []byte, err error) {
+	if size != sha256.Size && size != 2*sha256.Size {
+		return nil, nil, ErrInvalidEntropyLen
+	}
+
+	share = make([]byte, size)
+	if _, err = io.ReadFull(r, share); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return share, commit(share), nil
+}
+
+// CombineEntropy verifies every revealed share against its commitment and XORs them into the final entropy. The
+// result can be used as a 64-byte seed for Init or as 32 bytes of mnemonic entropy, depending on the share size.
+func CombineEntropy(shares, commitments [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNoShares
+	}
+
+	if len(shares) != len(commitments) {
+		return nil, fmt.Errorf("%w: %d shares and %d commitments", ErrShareMismatch, len(shares), len(commitments))
+	}
+
+	size := len(shares[0])
+	if size != sha256.Size && size != 2*sha256.Size {
+		return nil, ErrInvalidEntropyLen
+	}
+
+	var cheaters []int
+
+	for i, share := range shares {
+		if len(share) == 0 {
+			return nil, fmt.Errorf("%w: share %d is empty", ErrInvalidEntropyLen, i)
+		}
+
+		if len(share) != size {
+			return nil, fmt.Errorf("%w: share %d has %d bytes, expected %d", ErrShareMismatch, i, len(share), size)
+		}
+
+		if !bytes.Equal(commit(share), commitments[i]) {
+			cheaters = append(cheaters, i)
+		}
+
+		for j := 0; j < i; j++ {
+			if bytes.Equal(commitments[i], commitments[j]) {
+				return nil, fmt.Errorf("%w: participants %d and %d", ErrDuplicateShare, j, i)
+			}
+		}
+	}
+
+	if cheaters != nil {
+		return nil, &CommitmentError{Participants: cheaters}
+	}
+
+	entropy := make([]byte, size)
+
+	for _, share := range shares {
+		for i := range entropy {
+			entropy[i] ^= share[i]
+		}
+	}
+
+	return entropy, nil
+}
+
+// Transcript records an entropy ceremony for the audit record. It holds the published commitments and a commitment
+// to the result, never the shares, which would reveal the entropy.
+type Transcript struct {
+	Size        int      `json:"size"`
+	Commitments [][]byte `json:"commitments"`
+	Result      []byte   `json:"result"` // SHA-256 of the combined entropy
+}
+
+// NewTranscript records the commitments of a ceremony and the entropy it produced.
+func NewTranscript(commitments [][]byte, entropy []byte) *Transcript {
+	return &Transcript{
+		Size:        len(entropy),
+		Commitments: commitments,
+		Result:      commit(entropy),
+	}
+}
+
+// Verify reports whether entropy is the result recorded in the transcript.
+func (t *Transcript) Verify(entropy []byte) bool {
+	return len(entropy) == t.Size && bytes.Equal(commit(entropy), t.Result)
+}
+
+func commit(b []byte) []byte {
+	h := sha256.Sum256(b)
+
+	return h[:]
+}
@@ -0,0 +1,104 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCombineEntropy(t *testing.T) {
+	const participants = 3
+
+	var shares, commitments [][]byte
+
+	for i := 0; i < participants; i++ {
+		share, commitment, err := ContributeEntropySize(64)
+		if err != nil {
+			t.Fatalf("ContributeEntropySize %e", err)
+		}
+
+		shares, commitments = append(shares, share), append(commitments, commitment)
+	}
+
+	entropy, err := CombineEntropy(shares, commitments)
+	if err != nil {
+		t.Fatalf("CombineEntropy %e", err)
+	}
+
+	expected := make([]byte, 64)
+	for i := range expected {
+		expected[i] = shares[0][i] ^ shares[1][i] ^ shares[2][i]
+	}
+
+	if !bytes.Equal(entropy, expected) {
+		t.Errorf("Entropy does not match. Got:%x, expected:%x", entropy, expected)
+	}
+
+	if _, err = Init(entropy); err != nil {
+		t.Errorf("Init with combined entropy %e", err)
+	}
+
+	// the transcript survives serialization and does not contain the shares
+	data, err := json.Marshal(NewTranscript(commitments, entropy))
+	if err != nil {
+		t.Fatalf("Marshal %e", err)
+	}
+
+	var tr Transcript
+	if err = json.Unmarshal(data, &tr); err != nil {
+		t.Fatalf("Unmarshal %e", err)
+	}
+
+	if !tr.Verify(entropy) || tr.Verify(shares[0]) {
+		t.Errorf("Transcript does not verify the result")
+	}
+}
+
+func TestCombineEntropyErrors(t *testing.T) {
+	a, ca := ContributeEntropy()
+	b, cb := ContributeEntropy()
+	c, cc, _ := ContributeEntropySize(64)
+
+	if len(a) != 32 || !bytes.Equal(ca, commit(a)) {
+		t.Errorf("ContributeEntropy. Got:%x %x", a, ca)
+	}
+
+	if _, _, err := ContributeEntropySize(16); !errors.Is(err, ErrInvalidEntropyLen) {
+		t.Errorf("Expected ErrInvalidEntropyLen, got %v", err)
+	}
+
+	// a failing random source is reported
+	if d, cd, err := contributeEntropy(bytes.NewReader(make([]byte, 16)), 32); d != nil || cd != nil ||
+		!errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected no share and the error of the random source, got %x %x %v", d, cd, err)
+	}
+
+	tests := []struct {
+		shares, commitments [][]byte
+		err                 error
+	}{
+		{nil, nil, ErrNoShares},
+		{[][]byte{a, nil}, [][]byte{ca, nil}, ErrInvalidEntropyLen},
+		{[][]byte{a, b}, [][]byte{ca}, ErrShareMismatch},
+		{[][]byte{a, c}, [][]byte{ca, cc}, ErrShareMismatch},
+		{[][]byte{a[:16]}, [][]byte{ca}, ErrInvalidEntropyLen},
+		{[][]byte{a, a}, [][]byte{ca, ca}, ErrDuplicateShare},
+		{[][]byte{a, b}, [][]byte{ca, cc}, ErrCommitmentMismatch},
+	}
+
+	for i, tt := range tests {
+		if _, err := CombineEntropy(tt.shares, tt.commitments); !errors.Is(err, tt.err) {
+			t.Errorf("%d: expected %v, got %v", i, tt.err, err)
+		}
+	}
+
+	// the cheating participants are identified
+	_, err := CombineEntropy([][]byte{a, b, c[:32]}, [][]byte{ca, cc, cb})
+
+	var cerr *CommitmentError
+	if !errors.As(err, &cerr) || len(cerr.Participants) != 2 || cerr.Participants[0] != 1 || cerr.Participants[1] != 2 {
+		t.Errorf("Expected participants [1 2], got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package hd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// ExportFormat is the file format written by Export.
+type ExportFormat int
+
+// Export formats.
+const (
+	ExportJSON ExportFormat = iota // array of {"index", "address", "path", "privateKey"} objects
+	ExportCSV                      // index,address,path[,privateKey] with a header row
+)
+
+// ErrInvalidExportFormat will be reported for an unknown ExportFormat.
+var ErrInvalidExportFormat error = errors.New("hd: invalid export format")
+
+// exportRow is a JSON element written by Export.
+type exportRow struct {
+	Index      uint32 `json:"index"`
+	Address    string `json:"address"`
+	Path       string `json:"path"`
+	PrivateKey string `json:"privateKey,omitempty"`
+}
+
+// Export writes count BIP44 addresses of 'wallet' and flg, non-hardened as AddressBIP44 derives them and unlike
+// Address, from address number start to out, with their paths. Rows are streamed as they are derived. Without
+// includeKeys only public derivation is used after the branch, so it also works on watch-only wallets. Ethereum
+// addresses are EIP-55 checksummed, other coins hex encoded.
+// If a derivation fails, what was written so far is closed, so JSON output stays a valid array, and flushed, and the
+// error identifies the failed address number.
+func (w *HdWallet) Export(wallet uint32, flg uint8, start, count uint32, format ExportFormat, includeKeys bool,
+	out io.Writer,
+) error {
+	if format != ExportJSON && format != ExportCSV {
+		return ErrInvalidExportFormat
+	}
+
+	if includeKeys && w.WatchOnly() {
+		return ErrWatchOnly
+	}
+
+	br, release, err := w.branch(wallet, flg)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// derive leaves from the public branch so private keys are only touched when asked for; the neutered key
+	// shares the chain code of the cached branch, so it must not be wiped
+	if !includeKeys {
+		if br, err = br.Neuter(); err != nil {
+			return fmt.Errorf("%s: %w ", ErrInternal, err)
+		}
+	}
+
+	bw := bufio.NewWriter(out)
+	enc := newExportEncoder(format, bw, includeKeys)
+
+	if err = enc.begin(); err == nil {
+		err = w.exportRows(br, wallet, flg, start, count, includeKeys, enc)
+
+		if eerr := enc.end(); err == nil {
+			err = eerr
+		}
+	}
+
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+
+	return err
+}
+
+// exportRows derives the addresses of br and writes them with enc.
+func (w *HdWallet) exportRows(br *hdkeychain.ExtendedKey, wallet uint32, flg uint8, start, count uint32,
+	includeKeys bool, enc exportEncoder,
+) error {
+	for i := uint64(start); i < uint64(start)+uint64(count); i++ {
+		if i >= uint64(hardened) {
+			return fmt.Errorf("hd: address %d: %w", i, ErrIndexOutOfRange)
+		}
+
+		addrNum := uint32(i)
+
+		k, err := w.leaf(br, wallet, addrNum, addrNum)
+		if err != nil {
+			return fmt.Errorf("hd: address %d: %w", addrNum, err)
+		}
+
+		var addr, key []byte
+
+		if includeKeys {
+			addr, key, _ = w.keys(k)
+		} else {
+			pub, err := k.ECPubKey()
+			if err != nil {
+				k.Zero()
+
+				return fmt.Errorf("hd: address %d: %w", addrNum, err)
+			}
+
+			addr = w.encodeAddress(pub)
+		}
+
+		k.Zero()
+
+		err = enc.row(addrNum, w.exportAddress(addr), w.addressPath(wallet, flg, addrNum).String(), key)
+		zero(key)
+
+		if err != nil {
+			return fmt.Errorf("hd: address %d: %w", addrNum, err)
+		}
+	}
+
+	return nil
+}
+
+// exportAddress formats addr for the export.
+func (w *HdWallet) exportAddress(addr []byte) string {
+	if w.coin == CoinETH || w.coin == CoinETC {
+		return ChecksumAddress(addr)
+	}
+
+	return hex.EncodeToString(addr)
+}
+
+// exportEncoder writes the rows of an export in a given format.
+type exportEncoder interface {
+	begin() error
+	row(index uint32, addr, path string, key []byte) error
+	end() error
+}
+
+func newExportEncoder(format ExportFormat, bw *bufio.Writer, includeKeys bool) exportEncoder {
+	if format == ExportCSV {
+		return &csvEncoder{w: csv.NewWriter(bw), includeKeys: includeKeys}
+	}
+
+	return &jsonEncoder{w: bw}
+}
+
+// jsonEncoder streams a JSON array, one element per line.
+type jsonEncoder struct {
+	w    *bufio.Writer
+	rows int
+}
+
+func (e *jsonEncoder) begin() error {
+	_, err := e.w.WriteString("[")
+
+	return err
+}
+
+func (e *jsonEncoder) row(index uint32, addr, path string, key []byte) error {
+	b, err := json.Marshal(exportRow{Index: index, Address: addr, Path: path, PrivateKey: hex.EncodeToString(key)})
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if e.rows == 0 {
+		sep = "\n"
+	}
+
+	e.rows++
+
+	if _, err = e.w.WriteString(sep); err == nil {
+		_, err = e.w.Write(b)
+	}
+
+	zero(b)
+
+	return err
+}
+
+func (e *jsonEncoder) end() error {
+	_, err := e.w.WriteString("\n]\n")
+
+	return err
+}
+
+// csvEncoder writes CSV rows after a header row.
+type csvEncoder struct {
+	w           *csv.Writer
+	includeKeys bool
+}
+
+func (e *csvEncoder) begin() error {
+	header := []string{"index", "address", "path"}
+	if e.includeKeys {
+		header = append(header, "privateKey")
+	}
+
+	if err := e.w.Write(header); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+func (e *csvEncoder) row(index uint32, addr, path string, key []byte) error {
+	record := []string{strconv.FormatUint(uint64(index), 10), addr, path}
+	if e.includeKeys {
+		record = append(record, hex.EncodeToString(key))
+	}
+
+	if err := e.w.Write(record); err != nil {
+		return err
+	}
+	// hand the row over to the buffered output, so it is flushed even if a later row fails
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+func (e *csvEncoder) end() error {
+	e.w.Flush()
+
+	return e.w.Error()
+}
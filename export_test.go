@@ -0,0 +1,127 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	w := testWallet(t)
+
+	wo, err := w.Neuter(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		w           *HdWallet
+		format      ExportFormat
+		includeKeys bool
+	}{
+		{w, ExportJSON, false},
+		{w, ExportJSON, true},
+		{w, ExportCSV, false},
+		{w, ExportCSV, true},
+		{wo, ExportJSON, false},
+		{wo, ExportCSV, false},
+	}
+
+	for i, tc := range tt {
+		var out bytes.Buffer
+		if err = tc.w.Export(2, Change, 5, 10, tc.format, tc.includeKeys, &out); err != nil {
+			t.Fatalf("case %d: Export %v", i, err)
+		}
+
+		rows := parseExport(t, tc.format, out.Bytes())
+		if len(rows) != 10 {
+			t.Fatalf("case %d: Got:%d rows, expected:10", i, len(rows))
+		}
+
+		for j, row := range rows {
+			addr, key, _, _ := w.AddressBIP44(2, Change, 5+uint32(j))
+			if row.Index != 5+uint32(j) || row.Address != ChecksumAddress(addr) {
+				t.Errorf("case %d: Got:%d %s, expected:%d %s", i, row.Index, row.Address, 5+j, ChecksumAddress(addr))
+			}
+
+			if exp := fmt.Sprintf("m/44'/60'/2'/1/%d", 5+j); row.Path != exp {
+				t.Errorf("case %d: Got:%s, expected:%s", i, row.Path, exp)
+			}
+
+			exp := ""
+			if tc.includeKeys {
+				exp = hex.EncodeToString(key)
+			}
+
+			if row.PrivateKey != exp {
+				t.Errorf("case %d: Got:%s, expected:%s", i, row.PrivateKey, exp)
+			}
+		}
+	}
+
+	if err = wo.Export(2, External, 0, 1, ExportCSV, true, &bytes.Buffer{}); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+
+	err = w.Export(2, External, 0, 1, ExportFormat(9), false, &bytes.Buffer{})
+	if !errors.Is(err, ErrInvalidExportFormat) {
+		t.Errorf("Expected ErrInvalidExportFormat, got %v", err)
+	}
+}
+
+func TestExportError(t *testing.T) {
+	w := testWallet(t, WithLimits(Limits{MaxIndex: 3}))
+
+	for _, format := range []ExportFormat{ExportJSON, ExportCSV} {
+		var out bytes.Buffer
+
+		err := w.Export(0, External, 0, 10, format, false, &out)
+		if !errors.Is(err, ErrPolicyExceeded) || !strings.Contains(err.Error(), "address 4") {
+			t.Fatalf("Expected ErrPolicyExceeded at address 4, got %v", err)
+		}
+
+		// the rows derived before the failure are written, as a complete JSON array
+		if rows := parseExport(t, format, out.Bytes()); len(rows) != 4 || rows[3].Index != 3 {
+			t.Errorf("Got:%d rows, expected:4", len(rows))
+		}
+	}
+}
+
+func parseExport(t *testing.T, format ExportFormat, data []byte) []exportRow {
+	t.Helper()
+
+	var rows []exportRow
+
+	if format == ExportJSON {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			t.Fatalf("Unmarshal %v: %s", err, data)
+		}
+
+		return rows
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(records) == 0 || records[0][0] != "index" || records[0][1] != "address" ||
+		records[0][2] != "path" {
+		t.Fatalf("Invalid CSV %v: %s", err, data)
+	}
+
+	for _, r := range records[1:] {
+		index, _ := strconv.ParseUint(r[0], 10, 32)
+		row := exportRow{Index: uint32(index), Address: r[1], Path: r[2]}
+
+		if len(r) > 3 {
+			row.PrivateKey = r[3]
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
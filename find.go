@@ -0,0 +1,179 @@
+package hd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const findChunk = 64 // indices a FindAddress worker scans at a time
+
+// ErrNotFound will be reported when no address matches before the end of the index space.
+var ErrNotFound error = errors.New("hd: no matching address found")
+
+// FindAddress scans the addresses generated by Address for 'wallet' and flg from startIndex upwards, in parallel
+// across GOMAXPROCS workers, and returns the lowest index whose address satisfies match, which must be safe for
+// concurrent use. It stops with the error of ctx if it is done first, and with ErrNotFound once the index space,
+// which ends at 2^31 for the hardened address numbers of Address, is exhausted. An address that cannot be derived,
+// e.g. as it trips the limits of the wallet, ends the scan there: its error is returned unless a lower index matches,
+// so the result does not depend on the scheduling of the workers.
+func (w *HdWallet) FindAddress(ctx context.Context, wallet uint32, flg uint8, startIndex uint32,
+	match func(addr []byte) bool,
+) (index uint32, addr []byte, err error) {
+	if w.WatchOnly() {
+		return 0, nil, ErrWatchOnly
+	}
+
+	br, release, err := w.branch(wallet, flg)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer release()
+
+	s := &search{next: uint64(startIndex), best: uint64(hardened), errAt: uint64(hardened)}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for start, end, ok := s.chunk(); ok; start, end, ok = s.chunk() {
+				// yield between chunks, as js/wasm does not preempt goroutines and the timer of ctx could never run
+				runtime.Gosched()
+
+				for n := start; n < end && n < s.limit(); n++ {
+					if err := ctx.Err(); err != nil {
+						s.abort(err)
+
+						return
+					}
+
+					addr, err := w.hardenedLeaf(br, wallet, uint32(n))
+					if err != nil {
+						s.fail(n, fmt.Errorf("hd: address %d: %w", n, err))
+
+						break
+					}
+
+					if match(addr) {
+						s.found(n, addr)
+
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	switch {
+	case s.aborted != nil:
+		return 0, nil, s.aborted
+	case s.addr != nil && s.best < s.errAt:
+		return uint32(s.best), s.addr, nil
+	case s.err != nil:
+		return 0, nil, s.err
+	default:
+		return 0, nil, ErrNotFound
+	}
+}
+
+// hardenedLeaf returns the address of the hardened child addrNum of br.
+func (w *HdWallet) hardenedLeaf(br *hdkeychain.ExtendedKey, wallet, addrNum uint32) ([]byte, error) {
+	k, err := w.leaf(br, wallet, addrNum, hdkeychain.HardenedKeyStart+addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Zero()
+
+	pub, err := k.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return w.encodeAddress(pub), nil
+}
+
+// search hands out chunks of indices in increasing order and keeps the lowest match and the lowest failure. As
+// chunks below them are always scanned to the end, they are the lowest ones overall.
+type search struct {
+	mu      sync.Mutex
+	next    uint64 // start of the next chunk
+	best    uint64 // lowest matching index, or the end of the index space
+	addr    []byte
+	errAt   uint64 // lowest failing index, or the end of the index space
+	err     error
+	aborted error // error of the context, which stops the scan at once
+}
+
+// chunk returns the next range of indices to scan, if any is below the best match and the lowest failure.
+func (s *search) chunk() (start, end uint64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.aborted != nil || s.next >= s.stop() {
+		return 0, 0, false
+	}
+
+	start = s.next
+	s.next += findChunk
+
+	return start, s.next, true
+}
+
+// limit returns the index scans can stop at.
+func (s *search) limit() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.aborted != nil {
+		return 0
+	}
+
+	return s.stop()
+}
+
+// stop returns the lower of the best match and the lowest failure; s.mu must be held.
+func (s *search) stop() uint64 {
+	if s.errAt < s.best {
+		return s.errAt
+	}
+
+	return s.best
+}
+
+func (s *search) found(n uint64, addr []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n < s.best {
+		s.best, s.addr = n, addr
+	}
+}
+
+// fail records err as the failure of index n, if it is the lowest one.
+func (s *search) fail(n uint64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n < s.errAt {
+		s.errAt, s.err = n, err
+	}
+}
+
+func (s *search) abort(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.aborted == nil {
+		s.aborted = err
+	}
+}
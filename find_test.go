@@ -0,0 +1,99 @@
+package hd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindAddress(t *testing.T) {
+	w := testWallet(t)
+	lastZero := func(addr []byte) bool { return addr[len(addr)-1] == 0x00 }
+
+	for _, start := range []uint32{0, 300, 1000} {
+		index, addr, err := w.FindAddress(context.Background(), 2, External, start, lastZero)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the lowest matching index is returned, whatever the workers' scheduling
+		for n := start; n <= index; n++ {
+			exp, _, _, _ := w.Address(2, External, n)
+			if lastZero(exp) != (n == index) {
+				t.Fatalf("Index %d is not the lowest match from %d, %d is", index, start, n)
+			}
+
+			if n == index && !bytes.Equal(addr, exp) {
+				t.Errorf("Address does not match. Got:%x, expected:%x", addr, exp)
+			}
+		}
+	}
+
+	never := func([]byte) bool { return false }
+
+	// the end of the index space
+	if _, _, err := w.FindAddress(context.Background(), 2, External, hardened-100, never); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if _, _, err := w.FindAddress(context.Background(), 2, External, hardened, never); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := w.FindAddress(ctx, 2, External, 0, never); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// failures above the lowest match do not matter, whatever the workers' scheduling
+	first, _, _ := w.FindAddress(context.Background(), 2, External, 0, lastZero)
+
+	for i := 0; i < 5; i++ {
+		lw := testWallet(t, WithLimits(Limits{MaxIndex: first + 1}))
+		if index, _, err := lw.FindAddress(context.Background(), 2, External, 0, lastZero); err != nil ||
+			index != first {
+			t.Fatalf("Limited search. Got:%d, expected:%d, err:%v", index, first, err)
+		}
+
+		// and the lowest failure is reported when no index below it matches
+		lw.SetLimits(Limits{MaxIndex: first - 1})
+
+		_, _, err := lw.FindAddress(context.Background(), 2, External, 0, lastZero)
+		if !errors.Is(err, ErrPolicyExceeded) || !strings.Contains(err.Error(), fmt.Sprintf("address %d:", first)) {
+			t.Fatalf("Expected ErrPolicyExceeded at %d, got %v", first, err)
+		}
+	}
+
+	wo, _ := w.Neuter(2)
+	if _, _, err := wo.FindAddress(context.Background(), 2, External, 0, never); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+}
+
+func BenchmarkFindAddress(b *testing.B) {
+	w := testWallet(b)
+
+	// about 1 in 4096 addresses match
+	match := func(addr []byte) bool { return addr[len(addr)-1] == 0x00 && addr[len(addr)-2]&0x0f == 0 }
+
+	var scanned uint64
+
+	for n := 0; n < b.N; n++ {
+		start := uint32(n) * 10000
+
+		index, _, err := w.FindAddress(context.Background(), 0, External, start, match)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		scanned += uint64(index-start) + 1
+	}
+
+	b.ReportMetric(float64(scanned)/b.Elapsed().Seconds(), "addr/s")
+}
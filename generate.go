@@ -0,0 +1,145 @@
+package hd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// AddrResult is an address generated by GenerateRange, or the error deriving it.
+type AddrResult struct {
+	Index   uint32 // address number
+	Address []byte
+	Err     error
+}
+
+// GenerateRange generates count addresses of 'wallet' and flg from address number start, as Address would, spread
+// over workers goroutines (GOMAXPROCS if not positive). Results are delivered on the returned channel in increasing
+// address number order, a failed derivation being reported in the Err of its result, and the channel is closed after
+// the last one. If ctx is done first the channel is closed early, so callers should check ctx.Err once it is closed.
+// The channel must be read until it is closed, or ctx cancelled, for the goroutines to exit.
+func (w *HdWallet) GenerateRange(ctx context.Context, wallet uint32, flg uint8, start, count uint32, workers int,
+) (<-chan AddrResult, error) {
+	if w.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	if uint64(start)+uint64(count) > uint64(hardened) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	br, release, err := w.branch(wallet, flg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	jobs := make(chan rangeChunk)
+	// chunks are queued in order as they are handed out, so results are delivered in order whatever worker is first
+	queue := make(chan rangeChunk, workers)
+	results := make(chan AddrResult, findChunk)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1 + workers)
+
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		defer close(queue)
+
+		for n := uint64(start); n < uint64(start)+uint64(count); n += findChunk {
+			c := rangeChunk{start: n, end: n + findChunk, out: make(chan []AddrResult, 1)}
+			if c.end > uint64(start)+uint64(count) {
+				c.end = uint64(start) + uint64(count)
+			}
+
+			select {
+			case queue <- c:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- c:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for c := range jobs {
+				c.out <- w.generateChunk(ctx, br, wallet, c)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(results)
+
+		deliver(ctx, queue, results)
+
+		// stop the dispatcher and workers, and wait for them before wiping a branch key they may still use
+		cancel()
+		wg.Wait()
+		release()
+	}()
+
+	return results, nil
+}
+
+// rangeChunk is a range of address numbers derived by a GenerateRange worker.
+type rangeChunk struct {
+	start, end uint64
+	out        chan []AddrResult // buffered so workers never block on it
+}
+
+// generateChunk derives the addresses of c, stopping early if ctx is done.
+func (w *HdWallet) generateChunk(ctx context.Context, br *hdkeychain.ExtendedKey, wallet uint32, c rangeChunk,
+) []AddrResult {
+	rs := make([]AddrResult, 0, c.end-c.start)
+
+	for n := c.start; n < c.end && ctx.Err() == nil; n++ {
+		r := AddrResult{Index: uint32(n)}
+
+		if r.Address, r.Err = w.hardenedLeaf(br, wallet, uint32(n)); r.Err != nil {
+			r.Err = fmt.Errorf("hd: address %d: %w", n, r.Err)
+		}
+
+		rs = append(rs, r)
+	}
+
+	return rs
+}
+
+// deliver forwards the results of the queued chunks in order, until the queue is closed or ctx is done.
+func deliver(ctx context.Context, queue <-chan rangeChunk, results chan<- AddrResult) {
+	for c := range queue {
+		var rs []AddrResult
+
+		select {
+		case rs = <-c.out:
+		case <-ctx.Done():
+			return
+		}
+
+		for _, r := range rs {
+			select {
+			case results <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package hd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"go.uber.org/goleak"
+)
+
+// ignoreRuntime ignores the goroutine the js/wasm runtime keeps waiting for events.
+var ignoreRuntime = goleak.IgnoreAnyFunction("runtime.handleEvent")
+
+func TestGenerateRange(t *testing.T) {
+	defer goleak.VerifyNone(t, ignoreRuntime)
+
+	w := testWallet(t)
+
+	results, err := w.GenerateRange(context.Background(), 2, Change, 5, 150, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := uint32(5)
+
+	for r := range results {
+		if r.Err != nil || r.Index != next {
+			t.Fatalf("Got:%d %v, expected:%d", r.Index, r.Err, next)
+		}
+
+		if exp, _, _, _ := w.Address(2, Change, r.Index); !bytes.Equal(r.Address, exp) {
+			t.Errorf("%d: address does not match. Got:%x, expected:%x", r.Index, r.Address, exp)
+		}
+
+		next++
+	}
+
+	if next != 155 {
+		t.Errorf("Got:%d results, expected:150", next-5)
+	}
+
+	if _, err = w.GenerateRange(context.Background(), 2, Change, hardened-1, 2, 4); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	wo, _ := w.Neuter(2)
+	if _, err = wo.GenerateRange(context.Background(), 2, Change, 0, 1, 1); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+}
+
+func TestGenerateRangeErrors(t *testing.T) {
+	defer goleak.VerifyNone(t, ignoreRuntime)
+
+	w := testWallet(t, WithLimits(Limits{MaxIndex: 9}))
+
+	results, err := w.GenerateRange(context.Background(), 0, External, 0, 100, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n uint32
+
+	// failed indices are reported in their result, the others are still generated
+	for r := range results {
+		if r.Index != n || (r.Index > 9) != errors.Is(r.Err, ErrPolicyExceeded) {
+			t.Errorf("Got:%d %v, expected:%d", r.Index, r.Err, n)
+		}
+
+		n++
+	}
+
+	if n != 100 {
+		t.Errorf("Got:%d results, expected:100", n)
+	}
+}
+
+func TestGenerateRangeCancel(t *testing.T) {
+	defer goleak.VerifyNone(t, ignoreRuntime)
+
+	w := testWallet(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	results, err := w.GenerateRange(ctx, 0, External, 0, 100000, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+
+	for r := range results {
+		if n++; n == 10 {
+			cancel()
+		}
+
+		if r.Err != nil {
+			t.Fatalf("%d: %v", r.Index, r.Err)
+		}
+	}
+
+	if n >= 100000 || ctx.Err() == nil {
+		t.Errorf("Got:%d results, expected the generation to stop", n)
+	}
+
+	// abandoning the channel after cancelling does not leak either
+	ctx, cancel = context.WithCancel(context.Background())
+	if _, err = w.GenerateRange(ctx, 0, External, 0, 100000, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+}
+
+func BenchmarkGenerateRange(b *testing.B) {
+	w := testWallet(b)
+
+	counts := []int{1, 4}
+	if n := runtime.NumCPU(); n != 1 && n != 4 {
+		counts = append(counts, n)
+	}
+
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				results, err := w.GenerateRange(context.Background(), 0, External, 0, 50000, workers)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				for range results {
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package gethx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/tarancss/hd"
+)
+
+// TestAddressDifferential checks the keccak-256 and address encoding of package hd against go-ethereum's for the
+// keys of random seeds.
+func TestAddressDifferential(t *testing.T) {
+	seed, n := make([]byte, 64), 5000
+	if testing.Short() {
+		n = 200
+	}
+
+	for i := 0; i < n; i++ {
+		if _, err := rand.Read(seed); err != nil {
+			t.Fatal(err)
+		}
+
+		w, err := hd.Init(seed)
+		if err != nil {
+			t.Fatalf("Init %e", err)
+		}
+
+		addr, key, prv, err := w.Address(0, hd.External, uint32(i))
+		if err != nil {
+			t.Fatalf("Address %e", err)
+		}
+
+		if exp := crypto.PubkeyToAddress(prv.PublicKey).Bytes(); !bytes.Equal(addr, exp) {
+			t.Fatalf("Address does not match for key %x. Got:%x, expected:%x", key, addr, exp)
+		}
+
+		if exp := crypto.FromECDSA(&prv); !bytes.Equal(key, exp) {
+			t.Fatalf("Key does not match. Got:%x, expected:%x", key, exp)
+		}
+
+		comp, uncomp, _ := w.PublicKey(0, hd.External, uint32(i))
+		if exp := crypto.CompressPubkey(&prv.PublicKey); !bytes.Equal(comp, exp) {
+			t.Fatalf("Compressed key does not match. Got:%x, expected:%x", comp, exp)
+		}
+
+		if exp := crypto.FromECDSAPub(&prv.PublicKey); !bytes.Equal(uncomp, exp) {
+			t.Fatalf("Uncompressed key does not match. Got:%x, expected:%x", uncomp, exp)
+		}
+
+		_ = w.Close()
+	}
+}
+
+func TestSignMessageGeth(t *testing.T) {
+	w := testWallet(t)
+	msg := []byte("I own this address")
+
+	for i := uint32(0); i < 3; i++ {
+		sig, err := w.SignMessage(2, hd.External, i, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, key, _, _ := w.Address(2, hd.External, i)
+		prv, _ := crypto.ToECDSA(key)
+
+		// geth signs the same, with V as the recovery id
+		gsig, _ := crypto.Sign(accounts.TextHash(msg), prv)
+		if gsig[64] += recoveryBase; !bytes.Equal(sig, gsig) {
+			t.Errorf("Signature does not match geth. Got:%x, expected:%x", sig, gsig)
+		}
+
+		if hsig, _ := w.SignHash(2, hd.External, i, accounts.TextHash(msg)); !bytes.Equal(hsig, gsig) {
+			t.Errorf("SignHash does not match geth. Got:%x, expected:%x", hsig, gsig)
+		}
+	}
+}
+
+func TestECIESGeth(t *testing.T) {
+	w := testWallet(t)
+	msg := []byte("customer KYC blob")
+
+	_, key, prv, _ := w.Address(2, hd.External, 1)
+	gethKey, _ := crypto.ToECDSA(key)
+	gethPrv := ecies.ImportECDSA(gethKey)
+
+	ct, err := ecies.Encrypt(rand.Reader, &gethPrv.PublicKey, msg, nil, nil)
+	if err != nil {
+		t.Fatalf("ecies.Encrypt %e", err)
+	}
+
+	if pt, err := w.Decrypt(2, hd.External, 1, ct); err != nil || !bytes.Equal(pt, msg) {
+		t.Errorf("Decrypt of geth ciphertext does not match. Got:%q, err:%v", pt, err)
+	}
+
+	ct, _ = hd.Encrypt(crypto.FromECDSAPub(&prv.PublicKey), msg)
+	if pt, err := gethPrv.Decrypt(ct, nil, nil); err != nil || !bytes.Equal(pt, msg) {
+		t.Errorf("geth Decrypt does not match. Got:%q, err:%v", pt, err)
+	}
+}
+
+func TestKeystoreGeth(t *testing.T) {
+	w := testWallet(t)
+	_, key, _, _ := w.Address(2, hd.External, 1)
+
+	// geth reads the exported file
+	data, err := w.ExportKeystore(2, hd.External, 1, "secret", hd.WithScryptParams(hd.LightScryptN, hd.LightScryptP))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gk, err := keystore.DecryptKey(data, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(crypto.FromECDSA(gk.PrivateKey), key) || gk.Id.Version() != 4 {
+		t.Errorf("geth key does not match. Got:%x, expected:%x", crypto.FromECDSA(gk.PrivateKey), key)
+	}
+
+	// and the other way round
+	_, _, exp, _ := w.Address(2, hd.Change, 0)
+
+	data, err = keystore.EncryptKey(&keystore.Key{
+		Address:    crypto.PubkeyToAddress(exp.PublicKey),
+		PrivateKey: &exp,
+	}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prv, err := hd.ImportKeystore(data, "secret"); err != nil || prv.D.Cmp(exp.D) != 0 {
+		t.Errorf("Key does not match. Got:%x, expected:%x, err:%v", prv.D, exp.D, err)
+	}
+}
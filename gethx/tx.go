@@ -0,0 +1,82 @@
+// Package gethx integrates hd wallets with go-ethereum. It is kept apart so that the hd package does not depend on
+// go-ethereum.
+package gethx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tarancss/hd"
+)
+
+const recoveryBase = 27 // V of the signatures of hd.SignHash is 27 or 28
+
+var (
+	// ErrUnprotectedTx will be reported when signing a transaction without chain ID, unless AllowUnprotected is given.
+	ErrUnprotectedTx error = errors.New("gethx: refusing to sign a transaction without chain ID")
+	// ErrChainIDMismatch will be reported when the chain ID of a typed transaction is not the one given to SignTx.
+	ErrChainIDMismatch error = errors.New("gethx: transaction chain ID does not match")
+)
+
+// TxOption configures SignTx.
+type TxOption func(*txParams)
+
+type txParams struct {
+	allowUnprotected bool
+}
+
+// AllowUnprotected lets SignTx sign legacy transactions with a nil chainID, as before EIP-155. Such signatures can be
+// replayed on any chain.
+func AllowUnprotected() TxOption {
+	return func(tp *txParams) {
+		tp.allowUnprotected = true
+	}
+}
+
+// SignTx signs tx with the key of the address generated by w.Address for 'wallet', flg and address number. Legacy
+// transactions are signed as EIP-155 and typed transactions, e.g. EIP-1559 dynamic-fee ones, with the London signer.
+// A nil chainID is refused with ErrUnprotectedTx unless AllowUnprotected is given.
+func SignTx(w *hd.HdWallet, wallet uint32, flg uint8, addrNum uint32, tx *types.Transaction, chainID *big.Int,
+	opts ...TxOption,
+) (*types.Transaction, error) {
+	if w.Coin() != hd.CoinETH && w.Coin() != hd.CoinETC {
+		return nil, hd.ErrUnsupportedCoin
+	}
+
+	var tp txParams
+	for _, opt := range opts {
+		opt(&tp)
+	}
+
+	var signer types.Signer
+
+	switch {
+	case chainID != nil:
+		if tx.Type() != types.LegacyTxType && tx.ChainId().Cmp(chainID) != 0 {
+			return nil, fmt.Errorf("%w: %s, expected %s", ErrChainIDMismatch, tx.ChainId(), chainID)
+		}
+
+		signer = types.NewLondonSigner(chainID)
+	case tp.allowUnprotected && tx.Type() == types.LegacyTxType:
+		signer = types.HomesteadSigner{}
+	default:
+		return nil, ErrUnprotectedTx
+	}
+
+	sig, err := w.SignHash(wallet, flg, addrNum, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	// go-ethereum expects V as the recovery id
+	sig[len(sig)-1] -= recoveryBase
+
+	signed, err := tx.WithSignature(signer, sig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", hd.ErrInternal, err)
+	}
+
+	return signed, nil
+}
@@ -0,0 +1,91 @@
+package gethx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tarancss/hd"
+)
+
+// testSeed is the seed of the iancoleman vectors used in the tests of package hd.
+const testSeed = "642ce4e20f09c9f4d285c2b336063eaafbe4cb06dece8134f3a64bdd8f8c0c24df73e1a2e7056359b6db61e179ff45e5ada51d14f07b30becb6d92b961d35df4" //nolint:lll // seed literal is 128 digits
+
+// testWallet initializes a wallet from testSeed.
+func testWallet(tb testing.TB, opts ...hd.Option) *hd.HdWallet {
+	tb.Helper()
+
+	seed, _ := hex.DecodeString(testSeed)
+
+	w, err := hd.Init(seed, opts...)
+	if err != nil {
+		tb.Fatalf("Init %e", err)
+	}
+
+	return w
+}
+
+func TestSignTx(t *testing.T) {
+	w := testWallet(t)
+	to := common.HexToAddress("0x8A1847459c5FCD66f0B29012a21A2D5A314Ef1D0")
+	chainID := big.NewInt(1)
+
+	tests := []struct {
+		name string
+		tx   *types.Transaction
+	}{
+		{"legacy", types.NewTx(&types.LegacyTx{
+			Nonce: 1, GasPrice: big.NewInt(20e9), Gas: 21000, To: &to, Value: big.NewInt(1e18),
+		})},
+		{"dynamic fee", types.NewTx(&types.DynamicFeeTx{
+			ChainID: chainID, Nonce: 2, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(30e9), Gas: 21000, To: &to,
+			Value: big.NewInt(1e18),
+		})},
+	}
+
+	addr, _, _, _ := w.Address(2, hd.External, 1)
+
+	for _, tt := range tests {
+		signed, err := SignTx(w, 2, hd.External, 1, tt.tx, chainID)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+		if err != nil || !bytes.Equal(from.Bytes(), addr) {
+			t.Errorf("%s: sender does not match. Got:%x, expected:%x, err:%v", tt.name, from, addr, err)
+		}
+
+		if !signed.Protected() || signed.ChainId().Cmp(chainID) != 0 {
+			t.Errorf("%s: transaction is not replay protected", tt.name)
+		}
+	}
+
+	legacy := tests[0].tx
+
+	if _, err := SignTx(w, 2, hd.External, 1, legacy, nil); !errors.Is(err, ErrUnprotectedTx) {
+		t.Errorf("Expected ErrUnprotectedTx, got %v", err)
+	}
+
+	signed, err := SignTx(w, 2, hd.External, 1, legacy, nil, AllowUnprotected())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if from, _ := types.Sender(types.HomesteadSigner{}, signed); signed.Protected() || !bytes.Equal(from.Bytes(), addr) {
+		t.Errorf("Unprotected sender does not match. Got:%x, expected:%x", from, addr)
+	}
+
+	// typed transactions always carry a chain ID
+	if _, err := SignTx(w, 2, hd.External, 1, tests[1].tx, nil, AllowUnprotected()); !errors.Is(err, ErrUnprotectedTx) {
+		t.Errorf("Expected ErrUnprotectedTx, got %v", err)
+	}
+
+	if _, err := SignTx(w, 2, hd.External, 1, tests[1].tx, big.NewInt(5)); !errors.Is(err, ErrChainIDMismatch) {
+		t.Errorf("Expected ErrChainIDMismatch, got %v", err)
+	}
+}
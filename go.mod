@@ -3,15 +3,16 @@ module github.com/tarancss/hd
 go 1.18
 
 require (
+	github.com/btcsuite/btcd v0.23.2
 	github.com/btcsuite/btcd/btcec/v2 v2.3.2
 	github.com/btcsuite/btcd/btcutil v1.1.3
-	github.com/ethereum/go-ethereum v1.11.4
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1
+	go.uber.org/goleak v1.3.0
+	golang.org/x/crypto v0.1.0
+	golang.org/x/text v0.7.0
 )
 
 require (
-	github.com/btcsuite/btcd v0.23.2 // indirect
 	github.com/btcsuite/btcd/chaincfg/chainhash v1.0.1 // indirect
-	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
-	golang.org/x/crypto v0.1.0 // indirect
 	golang.org/x/sys v0.5.0 // indirect
 )
@@ -1,6 +1,6 @@
 // Package hd provides hierarchical deterministic wallet (HD wallet) functionality according to BIP39, BIP32 and BIP44.
 // The initialization of the wallet requires a 64-byte seed. It is recommended to generate seeds using BIP39 out of a
-// 24 word mnemonic and passphrase which are easy to remember.
+// 24 word mnemonic and passphrase which are easy to remember, see NewMnemonic and SeedFromMnemonic.
 // Once the HdWallet is initialized, you can easily generate any address.
 // For a full description of what a HD wallet is, please read: https://en.bitcoinwiki.org/wiki/Deterministic_wallet
 package hd
@@ -12,10 +12,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/btcsuite/btcd/chaincfg"
 )
 
 const (
@@ -24,9 +26,19 @@ const (
 	// Change addresses are used for internal use.
 	Change uint8 = 0x01
 
+	// SLIP-44 coin types that can be given to InitForCoin.
+	CoinBTC     uint32 = 0  // Bitcoin
+	CoinTestnet uint32 = 1  // Testnet, all coins
+	CoinETH     uint32 = 60 // Ethereum
+	CoinETC     uint32 = 61 // Ethereum Classic
+
 	purpose  uint32 = 44 // BIP44
-	coin     uint32 = 60 // Ethereum
 	hardened uint32 = 0x80000000
+
+	// HMAC keys used to generate the master node from the seed, one per curve as defined by SLIP-10.
+	masterKeySecp256k1 = "Bitcoin seed"
+	masterKeyNist256p1 = "Nist256p1 seed"
+	masterKeyEd25519   = "ed25519 seed"
 )
 
 var (
@@ -36,79 +48,359 @@ var (
 	ErrInvalidSeedLen error = errors.New("hd: length of seed is invalid")
 	// ErrUnusableSeed will be reported if the seed cannot be used.
 	ErrUnusableSeed error = errors.New("hd: the master key cannot be used")
+	// ErrClosed will be reported when using a wallet after it has been closed, or a Node after it has been zeroed.
+	ErrClosed error = errors.New("hd: wallet is closed")
+	// ErrPolicyExceeded will be reported when a derivation trips one of the wallet's Limits.
+	ErrPolicyExceeded error = errors.New("hd: policy limit exceeded")
+	// ErrInvalidCoinType will be reported for coin types that do not fit in a hardened index.
+	ErrInvalidCoinType error = errors.New("hd: invalid coin type")
+	// ErrIndexOutOfRange will be reported for indices that do not fit the requested derivation.
+	ErrIndexOutOfRange error = errors.New("hd: index out of range")
+	// ErrInvalidFlag will be reported for flags other than External and Change.
+	ErrInvalidFlag error = errors.New("hd: invalid flag")
+	// ErrInvalidHMACKey will be reported by Init when WithHMACKey sets the SLIP-10 key of another curve.
+	ErrInvalidHMACKey error = errors.New("hd: HMAC key is not for secp256k1")
 )
 
-// HdWallet is a composed type.
+// HdWallet is a BIP44 wallet rooted at m/44'/coin'. It is safe for concurrent use.
+// Earlier versions embedded *hdkeychain.ExtendedKey. Its methods IsPrivate, ECPubKey, ECPrivKey, ChainCode,
+// ChildIndex and IsForNet are kept as deprecated forwarders, and String now describes the wallet rather than
+// printing the branch key, which Serialize returns. Derive was removed; use DeriveChild or DeriveFromPath to derive
+// keys that stay on the BIP44 tree. Neuter now returns a watch-only wallet, and the other methods of the key are no
+// longer available.
 type HdWallet struct { //nolint:golint // changing would break compatibility
-	*hdkeychain.ExtendedKey // HD wallet branch from which account/addresses are generated
+	key        *hdkeychain.ExtendedKey            // m/44'/coin' branch from which accounts/addresses are generated
+	coin       uint32                             // SLIP-44 coin type of the branch
+	accounts   map[uint32]*hdkeychain.ExtendedKey // account public keys of a watch-only wallet
+	mu         sync.RWMutex                       // guards closed against in-flight derivations
+	closed     bool
+	policy     policy
+	cache      branchCache
+	net        *chaincfg.Params        // network of the serialized keys, mainnet if nil
+	master     *hdkeychain.ExtendedKey // master key, only retained with WithMasterKey
+	keepMaster bool
+	lenient    bool   // set by WithStrictMode(false)
+	hmacKey    string // HMAC key of the master node, set by WithHMACKey
+}
+
+// Init initializes the HD wallet for Ethereum for the given seed and options.
+func Init(seed []byte, opts ...Option) (*HdWallet, error) {
+	return InitForCoin(seed, CoinETH, opts...)
+}
+
+// WithStrictMode sets whether the wallet rejects invalid derivation inputs, which it does by default: flags other
+// than External and Change report ErrInvalidFlag, and wallet or address numbers of 2^31 or more ErrIndexOutOfRange.
+// Without strict mode, as in earlier versions, flags are masked with Change and numbers wrap around when hardened.
+func WithStrictMode(strict bool) Option {
+	return func(w *HdWallet) {
+		w.lenient = !strict
+	}
+}
+
+// WithHMACKey sets the HMAC-SHA512 key that generates the master node from the seed. Wallets derive secp256k1 keys,
+// for which BIP32 and SLIP-10 define "Bitcoin seed", the default. The SLIP-10 keys of other curves, "ed25519 seed"
+// and "Nist256p1 seed", are refused with ErrInvalidHMACKey; ed25519 keys are derived by InitSLIP10Ed25519. Any other
+// key yields keys no standard wallet derives from the seed, so it is only meant to match wallets that use it.
+func WithHMACKey(key string) Option {
+	return func(w *HdWallet) {
+		w.hmacKey = key
+	}
 }
 
-// Init initializes the HD wallet for Ethereum for the given seed.
-func Init(seed []byte) (*HdWallet, error) {
+// InitForCoin initializes the HD wallet on the m/44'/coinType' branch for the given seed and options.
+func InitForCoin(seed []byte, coinType uint32, opts ...Option) (*HdWallet, error) {
+	if coinType >= hardened {
+		return nil, ErrInvalidCoinType
+	}
+
+	w := &HdWallet{coin: coinType}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	hmacKey := w.hmacKey
+	switch hmacKey {
+	case "":
+		hmacKey = masterKeySecp256k1
+	case masterKeyEd25519, masterKeyNist256p1:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidHMACKey, hmacKey)
+	}
+
 	// generate a master wallet
-	master, err := getHdMaster(seed)
+	master, err := getHdMaster(seed, w.Network().HDPrivateKeyID[:], hmacKey)
 	if err != nil {
 		return nil, err
 	}
-	// generate a BIP44 and Ethereum branch
-	tmpW, err := master.Derive(hdkeychain.HardenedKeyStart + purpose)
+
+	if w.keepMaster {
+		// memoize the public key, so that concurrent derivations only read the key
+		if _, err = master.ECPubKey(); err != nil {
+			return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+		}
+
+		w.master = master
+	} else {
+		defer master.Zero()
+	}
+
+	// generate a BIP44 and coin branch
+	p := Path{hardened + purpose, hardened + coinType}
+
+	bip44, err := deriveChild(master, p, 0)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+		return nil, err
 	}
+	defer bip44.Zero()
 
-	tmpW, err = tmpW.Derive(hdkeychain.HardenedKeyStart + coin)
+	tmpW, err := deriveChild(bip44, p, 1)
 	if err != nil {
+		return nil, err
+	}
+
+	// memoize the public key, so that concurrent derivations only read the key
+	if _, err = tmpW.ECPubKey(); err != nil {
 		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
 	}
 
-	return &HdWallet{ExtendedKey: tmpW}, nil
+	w.key = tmpW
+
+	return w, nil
+}
+
+// Coin returns the SLIP-44 coin type the wallet was initialized for.
+func (w *HdWallet) Coin() uint32 {
+	return w.coin
 }
 
-// Address generates an address for 'wallet', flg should be either external or change and address number.
+// Address generates an address for 'wallet', flg should be either external or change and address number. For
+// Ethereum and Ethereum Classic the address is the 20-byte account address, for other coins it is the HASH160 of the
+// compressed public key, as used by Bitcoin P2PKH and P2WPKH addresses.
+// The address number is derived as a hardened index, m/44'/coin'/wallet'/flg/addrNum', which is not what other
+// BIP44 wallets use; see AddressBIP44. Wallet and address numbers range from 0 to 2^31-1, so the last address is
+// m/44'/coin'/2147483647'/1/2147483647'; see WithStrictMode for inputs out of range.
 func (w *HdWallet) Address(wallet uint32, flg uint8, addrNum uint32,
 ) (addr, key []byte, prv ecdsa.PrivateKey, err error) {
-	var tmpW *hdkeychain.ExtendedKey
-	// get account
-	tmpW, err = w.Derive(hdkeychain.HardenedKeyStart + wallet)
-	if err != nil {
+	if w.WatchOnly() {
+		err = ErrWatchOnly
+
 		return
 	}
-	// get external
-	tmpW, err = tmpW.Derive(uint32(flg & Change))
+
+	tmpW, err := w.derive(wallet, flg, addrNum, hdkeychain.HardenedKeyStart+addrNum)
 	if err != nil {
 		return
 	}
-	// get index to be used as address
-	tmpW, err = tmpW.Derive(hdkeychain.HardenedKeyStart + addrNum)
+
+	addr, key, prv = w.keys(tmpW)
+	tmpW.Zero()
+
+	return
+}
+
+// AddressBIP44 generates an address like Address but following BIP44, with a non-hardened address number:
+// m/44'/coin'/wallet'/flg/addrNum. These are the addresses MetaMask, Ledger or iancoleman.io derive by default.
+// addrNum must be below 2^31.
+func (w *HdWallet) AddressBIP44(wallet uint32, flg uint8, addrNum uint32,
+) (addr, key []byte, prv ecdsa.PrivateKey, err error) {
+	if w.WatchOnly() {
+		err = ErrWatchOnly
+
+		return
+	}
+
+	if addrNum >= hardened {
+		err = ErrIndexOutOfRange
+
+		return
+	}
+
+	tmpW, err := w.derive(wallet, flg, addrNum, addrNum)
 	if err != nil {
 		return
 	}
 
-	privateKey, _ := tmpW.ECPrivKey()
+	addr, key, prv = w.keys(tmpW)
+	tmpW.Zero()
+
+	return
+}
+
+// derive returns the key at m/44'/coin'/wallet'/flg/child, after checking the wallet is open and the policy allows
+// deriving addrNum.
+func (w *HdWallet) derive(wallet uint32, flg uint8, addrNum, child uint32) (*hdkeychain.ExtendedKey, error) {
+	p := w.addressPath(wallet, flg, child)
+
+	br, release, err := w.branch(wallet, flg)
+	if err != nil {
+		return nil, withPath(err, p)
+	}
+	defer release()
+
+	return w.leaf(br, wallet, addrNum, child)
+}
+
+// branch returns the key at m/44'/coin'/wallet'/flg, from which addresses are derived, and the func to call once
+// done with it. Branch keys are cached, see WithCacheSize.
+func (w *HdWallet) branch(wallet uint32, flg uint8) (*hdkeychain.ExtendedKey, func(), error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil, nil, ErrClosed
+	}
+
+	if flg != External && flg != Change && !w.lenient {
+		return nil, nil, fmt.Errorf("%w: %d", ErrInvalidFlag, flg)
+	}
+
+	return w.cache.acquire(branchKey{wallet: wallet, flg: flg & Change}, func() (*hdkeychain.ExtendedKey, error) {
+		// get account
+		tmpW, err := w.account(wallet)
+		if err != nil {
+			return nil, err
+		}
+		// watch-only wallets return the account key they hold, only wipe derived ones
+		if tmpW.IsPrivate() {
+			defer tmpW.Zero()
+		}
+		// get external
+		return deriveChild(tmpW, Path{hardened + purpose, hardened + w.coin, hardened + wallet, uint32(flg & Change)}, 3)
+	})
+}
+
+// leaf derives the child key of br to be used as address, after checking the wallet is open and the policy allows
+// deriving addrNum.
+func (w *HdWallet) leaf(br *hdkeychain.ExtendedKey, wallet, addrNum, child uint32) (*hdkeychain.ExtendedKey, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil, ErrClosed
+	}
+
+	if addrNum >= hardened && !w.lenient {
+		return nil, ErrIndexOutOfRange
+	}
+
+	if err := w.policy.allowDerivation(wallet, addrNum); err != nil {
+		return nil, err
+	}
+	// get index to be used as address
+	return deriveChild(br, Path{hardened + purpose, hardened + w.coin, hardened + wallet, br.ChildIndex(), child}, 4)
+}
+
+// keys returns the address, private key bytes and private key of the derived key k.
+func (w *HdWallet) keys(k *hdkeychain.ExtendedKey) (addr, key []byte, prv ecdsa.PrivateKey) {
+	return coinKeys(w.coin, k)
+}
+
+// encodeAddress returns the address bytes of pub for the coin of the wallet.
+func (w *HdWallet) encodeAddress(pub *btcec.PublicKey) []byte {
+	return coinAddress(w.coin, pub)
+}
+
+// coinKeys returns the address for coin, private key bytes and private key of the derived key k.
+func coinKeys(coin uint32, k *hdkeychain.ExtendedKey) (addr, key []byte, prv ecdsa.PrivateKey) {
+	privateKey, _ := k.ECPrivKey()
 	prv = *privateKey.ToECDSA()
 
-	return crypto.PubkeyToAddress(prv.PublicKey).Bytes(), crypto.FromECDSA(&prv), prv, nil
+	return coinAddress(coin, privateKey.PubKey()), privateKey.Serialize(), prv
 }
 
-// getHdMaster generates a Hd master wallet that can be used for many coins.
-func getHdMaster(seed []byte) (*hdkeychain.ExtendedKey, error) {
-	// Per [BIP32], the seed must be in range [MinSeedBytes, MaxSeedBytes].
-	if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
-		return nil, ErrInvalidSeedLen
+// coinAddress returns the address bytes of pub for coin.
+func coinAddress(coin uint32, pub *btcec.PublicKey) []byte {
+	switch coin {
+	case CoinETH, CoinETC:
+		return ethAddress(pub)
+	default:
+		return btcutil.Hash160(pub.SerializeCompressed())
 	}
+}
 
-	// First take the HMAC-SHA512 of the master key and the seed data:
-	//   I = HMAC-SHA512(Key = "Bitcoin seed", Data = S)
-	hmac512 := hmac.New(sha512.New, []byte("Bitcoin seed"))
-	if _, err := hmac512.Write(seed); err != nil {
-		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+// WithPrivateKey derives the private key of the address generated by Address for 'wallet', flg and address number and
+// calls fn with it. The key, and the intermediate keys derived for it, are wiped once fn returns, so fn must not keep
+// prv. The error of fn is returned as is.
+func (w *HdWallet) WithPrivateKey(wallet uint32, flg uint8, addrNum uint32, fn func(prv *ecdsa.PrivateKey) error,
+) error {
+	if w.WatchOnly() {
+		return ErrWatchOnly
 	}
 
-	lr := hmac512.Sum(nil)
+	k, err := w.derive(wallet, flg, addrNum, hdkeychain.HardenedKeyStart+addrNum)
+	if err != nil {
+		return err
+	}
+	defer k.Zero()
 
-	// Split "I" into two 32-byte sequences where left is master secret key and right is master chain code
-	secretKey := lr[:len(lr)/2]
-	chainCode := lr[len(lr)/2:]
+	privateKey, err := k.ECPrivKey()
+	if err != nil {
+		return fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+	defer privateKey.Zero()
+
+	prv := privateKey.ToECDSA()
+	defer zeroInt(prv.D)
+
+	return fn(prv)
+}
+
+// Zero wipes the key material of the wallet, like Close. Any later derivation returns ErrClosed.
+func (w *HdWallet) Zero() {
+	_ = w.Close()
+}
+
+// Close wipes the key material of the wallet. Closing waits for in-flight derivations to finish, and any later call
+// returns ErrClosed rather than deriving from the zeroed key. Closing a closed wallet is a no-op.
+func (w *HdWallet) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	w.cache.clear()
+
+	if w.key != nil {
+		w.key.Zero()
+	}
+
+	if w.master != nil {
+		w.master.Zero()
+	}
+
+	for _, acct := range w.accounts {
+		acct.Zero()
+	}
+
+	return nil
+}
+
+// zero overwrites b, to be used on key material once it is no longer needed.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroInt overwrites the words of n and sets it to 0.
+func zeroInt(n *big.Int) {
+	words := n.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+
+	n.SetInt64(0)
+}
+
+// getHdMaster generates a Hd master wallet that can be used for many coins, with the given private key version bytes
+// and HMAC key.
+func getHdMaster(seed, version []byte, hmacKey string) (*hdkeychain.ExtendedKey, error) {
+	secretKey, chainCode, err := masterSecret(seed, hmacKey)
+	if err != nil {
+		return nil, err
+	}
 
 	// Ensure the key is usable.
 	secretKeyNum := new(big.Int).SetBytes(secretKey)
@@ -118,6 +410,26 @@ func getHdMaster(seed []byte) (*hdkeychain.ExtendedKey, error) {
 
 	parentFP := []byte{0x00, 0x00, 0x00, 0x00}
 
-	return hdkeychain.NewExtendedKey([]byte{0x04, 0x88, 0xad, 0xe4}, secretKey, chainCode,
-		parentFP, 0, 0, true), nil // starts with xprv -> {0x04, 0x88, 0xad, 0xe4}
+	return hdkeychain.NewExtendedKey(version, secretKey, chainCode, parentFP, 0, 0, true), nil
+}
+
+// masterSecret returns the master secret key and chain code for the seed, using hmacKey as the HMAC-SHA512 key. It
+// does not check the secret is a valid scalar, as that depends on the curve it will be used with.
+func masterSecret(seed []byte, hmacKey string) (secretKey, chainCode []byte, err error) {
+	// Per [BIP32], the seed must be in range [MinSeedBytes, MaxSeedBytes].
+	if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
+		return nil, nil, ErrInvalidSeedLen
+	}
+
+	// First take the HMAC-SHA512 of the master key and the seed data:
+	//   I = HMAC-SHA512(Key = hmacKey, Data = S)
+	hmac512 := hmac.New(sha512.New, []byte(hmacKey))
+	if _, err = hmac512.Write(seed); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	lr := hmac512.Sum(nil)
+
+	// Split "I" into two 32-byte sequences where left is master secret key and right is master chain code
+	return lr[:len(lr)/2], lr[len(lr)/2:], nil
 }
@@ -4,8 +4,13 @@ package hd
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
+	"sync"
 	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
 )
 
 func TestHdWallet(t *testing.T) {
@@ -44,4 +49,516 @@ func TestHdWallet(t *testing.T) {
 			t.Errorf("Key %d does not match. Got:%x, expected:%x", i, key, keyExp)
 		}
 	}
+
+	// the same vectors through SelfCheck, as applications would run them
+	vectors := make([]Vector, len(expected))
+	for i, exp := range expected {
+		vectors[i] = Vector{Wallet: 2, Flag: External, Index: uint32(i), Address: exp[0], Key: exp[1]}
+	}
+
+	if err = w.SelfCheck(vectors); err != nil {
+		t.Errorf("SelfCheck %v", err)
+	}
+}
+
+func TestMasterSecret(t *testing.T) {
+	// SLIP-10 test vectors 1 and 2, master node for each curve.
+	const (
+		seed1 = "000102030405060708090a0b0c0d0e0f"
+		seed2 = "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542" //nolint:lll // seed literal is 128 digits
+	)
+
+	tests := []struct {
+		seed, hmacKey, chainCode, key string
+	}{
+		{
+			seed1, masterKeySecp256k1,
+			"873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+			"e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		},
+		{
+			seed2, masterKeySecp256k1,
+			"60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689",
+			"4b03d6fc340455b363f51020ad3ecca4f0850280cf436c70c727923f6db46c3e",
+		},
+		{
+			seed1, masterKeyNist256p1,
+			"beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea",
+			"612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2",
+		},
+		{
+			seed1, masterKeyEd25519,
+			"90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		},
+		{
+			seed2, masterKeyEd25519,
+			"ef70a74db9c3a5af931b5fe73ed8e1a53464133654fd55e7a66f8570b8e33c3b",
+			"171cb88b1b3c1db25add599712e36245d75bc65a1a5c9e18d76f9f2b1eab4012",
+		},
+	}
+
+	for i, tt := range tests {
+		seed, _ := hex.DecodeString(tt.seed)
+
+		key, chainCode, err := masterSecret(seed, tt.hmacKey)
+		if err != nil {
+			t.Errorf("masterSecret %d: %e", i, err)
+
+			continue
+		}
+
+		if hex.EncodeToString(chainCode) != tt.chainCode {
+			t.Errorf("Chain code %d does not match. Got:%x, expected:%s", i, chainCode, tt.chainCode)
+		}
+
+		if hex.EncodeToString(key) != tt.key {
+			t.Errorf("Key %d does not match. Got:%x, expected:%s", i, key, tt.key)
+		}
+	}
+
+	// the secp256k1 master must keep serializing as the BIP32 test vector 1 root
+	seed, _ := hex.DecodeString(seed1)
+
+	master, err := getHdMaster(seed, chaincfg.MainNetParams.HDPrivateKeyID[:], masterKeySecp256k1)
+	if err != nil {
+		t.Fatalf("getHdMaster: %e", err)
+	}
+
+	if xprv := master.String(); xprv != "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi" { //nolint:lll // xprv literal
+		t.Errorf("Master does not match. Got:%s", xprv)
+	}
+
+	if _, _, err = masterSecret(seed[:15], masterKeyEd25519); !errors.Is(err, ErrInvalidSeedLen) {
+		t.Errorf("Expected ErrInvalidSeedLen, got %v", err)
+	}
+}
+
+func TestWithHMACKey(t *testing.T) {
+	const (
+		seed1 = "000102030405060708090a0b0c0d0e0f"
+		seed2 = "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542" //nolint:lll // seed literal is 128 digits
+	)
+
+	// SLIP-10 secp256k1 test vectors 1 (chain m/0H/1) and 2 (chain m/0), derived from the master key of the wallet
+	tests := []struct {
+		seed, hmacKey string
+		path          Path
+		chainCode     string
+		key           string
+	}{
+		{
+			seed1, "", Path{hardened, 1},
+			"2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19",
+			"3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+		},
+		{
+			seed1, masterKeySecp256k1, Path{hardened, 1},
+			"2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19",
+			"3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+		},
+		{
+			seed2, masterKeySecp256k1, Path{0},
+			"f0909affaa7ee7abe5dd4e100598d4dc53cd709d5a5c2cac40e7412f232f7c9c",
+			"abe74a98f6c7eabee0428f53798f0ab8aa1bd37873999041703c742f15ac7e1e",
+		},
+	}
+
+	for i, tt := range tests {
+		seed, _ := hex.DecodeString(tt.seed)
+
+		opts := []Option{WithMasterKey()}
+		if tt.hmacKey != "" {
+			opts = append(opts, WithHMACKey(tt.hmacKey))
+		}
+
+		w, err := Init(seed, opts...)
+		if err != nil {
+			t.Fatalf("%d: Init %e", i, err)
+		}
+
+		k := w.master
+		for j := range tt.path {
+			if k, err = deriveChild(k, tt.path, j); err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+		}
+
+		prv, _ := k.ECPrivKey()
+		if key := prv.Key.Bytes(); hex.EncodeToString(key[:]) != tt.key {
+			t.Errorf("%d: key does not match. Got:%x, expected:%s", i, key, tt.key)
+		}
+
+		if hex.EncodeToString(k.ChainCode()) != tt.chainCode {
+			t.Errorf("%d: chain code does not match. Got:%x, expected:%s", i, k.ChainCode(), tt.chainCode)
+		}
+	}
+
+	// the keys of other curves would give secp256k1 keys no wallet of that curve has
+	seed, _ := hex.DecodeString(seed1)
+
+	for _, key := range []string{masterKeyEd25519, masterKeyNist256p1} {
+		if _, err := Init(seed, WithHMACKey(key)); !errors.Is(err, ErrInvalidHMACKey) {
+			t.Errorf("%s: expected ErrInvalidHMACKey, got %v", key, err)
+		}
+	}
+}
+
+// testSeed is the seed of the iancoleman vectors used in TestHdWallet.
+const testSeed = "642ce4e20f09c9f4d285c2b336063eaafbe4cb06dece8134f3a64bdd8f8c0c24df73e1a2e7056359b6db61e179ff45e5ada51d14f07b30becb6d92b961d35df4" //nolint:lll // seed literal is 128 digits
+
+// testWallet initializes a wallet from testSeed.
+func testWallet(tb testing.TB, opts ...Option) *HdWallet {
+	tb.Helper()
+
+	seed, _ := hex.DecodeString(testSeed)
+
+	w, err := Init(seed, opts...)
+	if err != nil {
+		tb.Fatalf("Init %e", err)
+	}
+
+	return w
+}
+
+func TestClose(t *testing.T) {
+	addrExp, _ := hex.DecodeString("D43E2870777916Ede1f5Cc43F14f8C0741e11f96")
+	w := testWallet(t)
+
+	// in-flight derivations either complete with the right address or see the wallet closed
+	var wg sync.WaitGroup
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				addr, _, _, err := w.Address(uint32(2), External, 0)
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+
+				if err != nil || !bytes.Equal(addr, addrExp) {
+					t.Errorf("Address during Close. Got:%x, err:%v", addr, err)
+
+					return
+				}
+			}
+		}()
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close %e", err)
+	}
+
+	wg.Wait()
+
+	if _, _, _, err := w.Address(uint32(2), External, 0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Address after Close. Expected ErrClosed, got %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Second Close should be a no-op, got %v", err)
+	}
+}
+
+func TestWithPrivateKey(t *testing.T) {
+	w := testWallet(t)
+	_, key, _, _ := w.Address(2, External, 1)
+
+	var held *ecdsa.PrivateKey
+
+	err := w.WithPrivateKey(2, External, 1, func(prv *ecdsa.PrivateKey) error {
+		if got := prv.D.FillBytes(make([]byte, 32)); !bytes.Equal(got, key) {
+			t.Errorf("Key does not match. Got:%x, expected:%x", got, key)
+		}
+
+		held = prv
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the key is wiped once the callback returns
+	if held.D.Sign() != 0 || len(held.D.Bits()) != 0 {
+		t.Errorf("Key was not wiped. Got:%x", held.D)
+	}
+
+	errFn := errors.New("callback failed")
+	if err = w.WithPrivateKey(2, External, 1, func(*ecdsa.PrivateKey) error { return errFn }); !errors.Is(err, errFn) {
+		t.Errorf("Expected the callback error, got %v", err)
+	}
+
+	// a wiped wallet refuses to derive
+	w.Zero()
+
+	if err = w.WithPrivateKey(2, External, 1, func(*ecdsa.PrivateKey) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+
+	if _, _, _, err = w.Address(2, External, 1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+func TestInitForCoin(t *testing.T) {
+	seed, _ := SeedFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon "+
+		"abandon about", "")
+
+	// iancoleman.io, BIP44 tab, m/44'/coin'/0'/0/i: the private keys and HASH160 of the addresses decoded from the
+	// WIF and P2PKH strings shown there, the same vectors as TestBTCAddress
+	tests := []struct {
+		coin      uint32
+		addr, key [2]string
+	}{
+		{
+			CoinBTC,
+			[2]string{"d986ed01b7a22225a70edbf2ba7cfb63a15cb3aa", "6ae1301cf44ca525751d1763ac4fef12d1153986"},
+			[2]string{
+				"e284129cc0922579a535bbf4d1a3b25773090d28c909bc0fed73b5e0222cc372",
+				"5c1141f60edd3095579529db7e88d964cb0a9ec0f814f6a10cd5cbd763078a0c",
+			},
+		},
+		{
+			CoinTestnet,
+			[2]string{"3a2d4145a4f098523b3e8127f1da87cfc55b8e79", "d3c0870e8e13a9ec320f1280889127aa15a4c0a1"},
+			[2]string{
+				"e01fea8a48e2854fdd0255c12b1d704967d9401f11c3f4980006ced8977574dc",
+				"c4640899c331482720477a47ccb60d447502fbda8aae1d35a36d1866e04cc4c5",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		w, err := InitForCoin(seed, tt.coin)
+		if err != nil {
+			t.Fatalf("InitForCoin(%d) %e", tt.coin, err)
+		}
+
+		if w.Coin() != tt.coin {
+			t.Errorf("Coin does not match. Got:%d, expected:%d", w.Coin(), tt.coin)
+		}
+
+		for i := uint32(0); i < 2; i++ {
+			addr, key, _, err := w.AddressBIP44(0, External, i)
+			if err != nil {
+				t.Fatalf("AddressBIP44 %d: %e", i, err)
+			}
+
+			if hex.EncodeToString(key) != tt.key[i] {
+				t.Errorf("Coin %d key %d does not match. Got:%x, expected:%s", tt.coin, i, key, tt.key[i])
+			}
+
+			if hex.EncodeToString(addr) != tt.addr[i] {
+				t.Errorf("Coin %d address %d does not match. Got:%x, expected:%s", tt.coin, i, addr, tt.addr[i])
+			}
+		}
+	}
+
+	// Ethereum Classic keeps the Ethereum address format on its own branch
+	etc, err := InitForCoin(seed, CoinETC)
+	if err != nil || etc.Coin() != CoinETC {
+		t.Fatalf("InitForCoin(%d) %v", CoinETC, err)
+	}
+
+	addr, key, prv, _ := etc.AddressBIP44(0, External, 0)
+	if !bytes.Equal(addr, testEthAddress(&prv)) {
+		t.Errorf("ETC address does not match its key. Got:%x, expected:%x", addr, testEthAddress(&prv))
+	}
+
+	eth, _ := Init(seed)
+	if _, ethKey, _, _ := eth.AddressBIP44(0, External, 0); bytes.Equal(key, ethKey) {
+		t.Errorf("ETC key is the Ethereum key")
+	}
+
+	if _, err := InitForCoin(seed, hardened); !errors.Is(err, ErrInvalidCoinType) {
+		t.Errorf("Expected ErrInvalidCoinType, got %v", err)
+	}
+}
+
+func TestAddressBIP44(t *testing.T) {
+	// canonical m/44'/60'/0'/0/i accounts, as shown by MetaMask or Hardhat for these mnemonics
+	tests := []struct {
+		mnemonic  string
+		wallet, i uint32
+		addr, key string
+	}{
+		{
+			"test test test test test test test test test test test junk", 0, 0,
+			"f39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+			"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+		},
+		{
+			"test test test test test test test test test test test junk", 0, 1,
+			"70997970C51812dc3A010C7d01b50e0d17dc79C8",
+			"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
+		},
+		{
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", 0, 0,
+			"9858EfFD232B4033E47d90003D41EC34EcaEda94",
+			"1ab42cc412b618bdea3a599e3c9bae199ebf030895b039e9db1e30dafb12b727",
+		},
+		{testMnemonic, 2, 0, "", "31d97e9a0cf429a3fd9c7713a386aa5cddac69c59d2e3761a5586977a4119812"},
+		{testMnemonic, 2, 2, "", "36ab0057ba96c66bf3bd9cf9cfb4ee129c1b6f69443c6cf52abbf9a8221fe220"},
+	}
+
+	for i, tt := range tests {
+		passphrase := ""
+		if tt.mnemonic == testMnemonic {
+			passphrase = "password"
+		}
+
+		seed, _ := SeedFromMnemonic(tt.mnemonic, passphrase)
+
+		w, err := Init(seed)
+		if err != nil {
+			t.Fatalf("Init %e", err)
+		}
+
+		addr, key, _, err := w.AddressBIP44(tt.wallet, External, tt.i)
+		if err != nil {
+			t.Fatalf("%d: AddressBIP44 %e", i, err)
+		}
+
+		if addrExp, _ := hex.DecodeString(tt.addr); tt.addr != "" && !bytes.Equal(addr, addrExp) {
+			t.Errorf("%d: address does not match. Got:%x, expected:%s", i, addr, tt.addr)
+		}
+
+		if hex.EncodeToString(key) != tt.key {
+			t.Errorf("%d: key does not match. Got:%x, expected:%s", i, key, tt.key)
+		}
+
+		// the hardened scheme of Address derives different keys
+		if _, keyH, _, _ := w.Address(tt.wallet, External, tt.i); bytes.Equal(key, keyH) {
+			t.Errorf("%d: AddressBIP44 and Address derive the same key", i)
+		}
+	}
+
+	w := testWallet(t)
+	if _, _, _, err := w.AddressBIP44(0, External, hardened); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	w := testWallet(t)
+	lw := testWallet(t, WithStrictMode(false))
+
+	// boundary inputs derive the documented paths
+	boundaries := []struct {
+		wallet  uint32
+		flg     uint8
+		addrNum uint32
+		path    string
+	}{
+		{0, External, 0, "m/44'/60'/0'/0/0'"},
+		{hardened - 1, Change, hardened - 1, "m/44'/60'/2147483647'/1/2147483647'"},
+	}
+
+	for _, tt := range boundaries {
+		addr, _, _, err := w.Address(tt.wallet, tt.flg, tt.addrNum)
+		if exp, _, _ := w.AddressAtPath(tt.path); err != nil || !bytes.Equal(addr, exp) {
+			t.Errorf("%s: Got:%x, expected:%x, err:%v", tt.path, addr, exp, err)
+		}
+	}
+
+	rejected := []struct {
+		wallet  uint32
+		flg     uint8
+		addrNum uint32
+		err     error
+	}{
+		{0, 2, 0, ErrInvalidFlag},
+		{0, 7, 0, ErrInvalidFlag},
+		{0, 0xff, 0, ErrInvalidFlag},
+		{hardened, External, 0, ErrIndexOutOfRange},
+		{0xffffffff, External, 0, ErrIndexOutOfRange},
+		{0, External, hardened, ErrIndexOutOfRange},
+		{0, Change, 0xffffffff, ErrIndexOutOfRange},
+	}
+
+	for _, tt := range rejected {
+		if _, _, _, err := w.Address(tt.wallet, tt.flg, tt.addrNum); !errors.Is(err, tt.err) {
+			t.Errorf("Address(%d, %d, %d): expected %v, got %v", tt.wallet, tt.flg, tt.addrNum, tt.err, err)
+		}
+
+		if tt.addrNum < hardened {
+			if _, _, _, err := w.AddressBIP44(tt.wallet, tt.flg, tt.addrNum); !errors.Is(err, tt.err) {
+				t.Errorf("AddressBIP44(%d, %d, %d): expected %v, got %v", tt.wallet, tt.flg, tt.addrNum, tt.err, err)
+			}
+
+			if _, err := w.PublicAddress(tt.wallet, tt.flg, tt.addrNum); !errors.Is(err, tt.err) {
+				t.Errorf("PublicAddress(%d, %d, %d): expected %v, got %v", tt.wallet, tt.flg, tt.addrNum, tt.err, err)
+			}
+		}
+	}
+
+	if _, err := w.ExportXpub(hardened); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	if _, err := w.Addresses(0, 4, 0, 1, false); !errors.Is(err, ErrInvalidFlag) {
+		t.Errorf("Expected ErrInvalidFlag, got %v", err)
+	}
+
+	// without strict mode flags are masked and hardened address numbers wrap around, as before
+	masked, _, _, err := lw.Address(2, 7, 1)
+	if exp, _, _, _ := w.Address(2, Change, 1); err != nil || !bytes.Equal(masked, exp) {
+		t.Errorf("Masked flag. Got:%x, expected:%x, err:%v", masked, exp, err)
+	}
+
+	wrapped, _, _, err := lw.Address(0, External, hardened+5)
+	if exp, _, _, _ := w.AddressBIP44(0, External, 5); err != nil || !bytes.Equal(wrapped, exp) {
+		t.Errorf("Wrapped index. Got:%x, expected:%x, err:%v", wrapped, exp, err)
+	}
+
+	// valid inputs derive the same addresses in both modes
+	for _, flg := range []uint8{External, Change} {
+		for i := uint32(0); i < 3; i++ {
+			a, _, _, _ := w.Address(i, flg, i)
+			if b, _, _, _ := lw.Address(i, flg, i); !bytes.Equal(a, b) {
+				t.Errorf("Address(%d, %d, %d) differs. Got:%x, expected:%x", i, flg, i, b, a)
+			}
+		}
+	}
+}
+
+func FuzzInit(f *testing.F) {
+	seed, _ := hex.DecodeString(testSeed)
+	f.Add(seed)
+	f.Add(seed[:16])
+	f.Add(seed[:15])
+	f.Add(append(seed, 0))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, seed []byte) {
+		w, err := Init(seed)
+
+		valid := len(seed) >= 16 && len(seed) <= 64
+		if !valid {
+			if !errors.Is(err, ErrInvalidSeedLen) {
+				t.Fatalf("%d bytes: expected ErrInvalidSeedLen, got %v", len(seed), err)
+			}
+
+			return
+		}
+
+		// keys out of range are possible but have a negligible probability
+		if errors.Is(err, ErrUnusableSeed) {
+			return
+		}
+
+		if err != nil {
+			t.Fatalf("%d bytes: Init %v", len(seed), err)
+		}
+
+		a, _, _, err := w.Address(0, External, 0)
+		if b, _ := w.PublicAddress(0, External, 0); err != nil || len(a) != 20 || len(b) != 20 {
+			t.Errorf("%d bytes: got %x %x, err:%v", len(seed), a, b, err)
+		}
+	})
 }
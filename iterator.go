@@ -0,0 +1,113 @@
+package hd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// AddrIterator derives the addresses of a wallet and flg lazily, one per call to Next. Iterators created from the
+// same wallet are independent and can be used concurrently, but a single AddrIterator is not safe for concurrent use.
+type AddrIterator struct {
+	w       *HdWallet
+	ctx     context.Context
+	wallet  uint32
+	br      *hdkeychain.ExtendedKey
+	release func()
+	next    uint64 // address number of the next call to Next
+	skipped int
+	done    bool
+	err     error
+}
+
+// Iterator returns an iterator over the addresses generated by Address for 'wallet' and flg from startIndex upwards.
+// It stops when ctx is done, on a derivation failure, or once the hardened address numbers are exhausted at 2^31.
+// Address numbers whose key is invalid, which happens with a probability below 1 in 2^127, are skipped. Call Close
+// to release the branch key if the iteration is abandoned before it stops.
+func (w *HdWallet) Iterator(ctx context.Context, wallet uint32, flg uint8, startIndex uint32) *AddrIterator {
+	it := &AddrIterator{w: w, ctx: ctx, wallet: wallet, next: uint64(startIndex)}
+
+	if w.WatchOnly() {
+		it.stop(ErrWatchOnly)
+
+		return it
+	}
+
+	br, release, err := w.branch(wallet, flg)
+	if err != nil {
+		it.stop(err)
+
+		return it
+	}
+
+	it.br, it.release = br, release
+
+	return it
+}
+
+// Next derives the next address and returns its address number, or ok false once the iterator stopped. Err then
+// reports why.
+func (it *AddrIterator) Next() (index uint32, addr []byte, ok bool) {
+	for !it.done {
+		if it.next >= uint64(hardened) {
+			it.stop(nil)
+
+			break
+		}
+
+		if err := it.ctx.Err(); err != nil {
+			it.stop(err)
+
+			break
+		}
+
+		n := uint32(it.next)
+		it.next++
+
+		addr, err := it.w.hardenedLeaf(it.br, it.wallet, n)
+		if errors.Is(err, hdkeychain.ErrInvalidChild) {
+			it.skipped++
+
+			continue
+		}
+
+		if err != nil {
+			it.stop(fmt.Errorf("hd: address %d: %w", n, err))
+
+			break
+		}
+
+		return n, addr, true
+	}
+
+	return 0, nil, false
+}
+
+// Err returns the error that stopped the iterator, nil if it is still running or the index space was exhausted.
+func (it *AddrIterator) Err() error {
+	return it.err
+}
+
+// Skipped returns the number of invalid address numbers skipped so far.
+func (it *AddrIterator) Skipped() int {
+	return it.skipped
+}
+
+// Close stops the iterator and releases the branch key. Next returns ok false afterwards, and Err nil unless the
+// iterator had already stopped on an error.
+func (it *AddrIterator) Close() {
+	if !it.done {
+		it.stop(nil)
+	}
+}
+
+func (it *AddrIterator) stop(err error) {
+	it.done, it.err = true, err
+
+	if it.release != nil {
+		it.release()
+		it.br, it.release = nil, nil
+	}
+}
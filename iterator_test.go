@@ -0,0 +1,105 @@
+package hd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	w := testWallet(t)
+
+	it := w.Iterator(context.Background(), 2, Change, 10)
+	defer it.Close()
+
+	for i := uint32(10); i < 20; i++ {
+		index, addr, ok := it.Next()
+		if !ok || index != i {
+			t.Fatalf("Got:%d %t, expected:%d, err:%v", index, ok, i, it.Err())
+		}
+
+		if exp, _, _, _ := w.Address(2, Change, i); !bytes.Equal(addr, exp) {
+			t.Errorf("Address does not match. Got:%x, expected:%x", addr, exp)
+		}
+	}
+
+	if it.Skipped() != 0 {
+		t.Errorf("Got:%d skipped, expected:0", it.Skipped())
+	}
+
+	it.Close()
+
+	if _, _, ok := it.Next(); ok || it.Err() != nil {
+		t.Errorf("Expected a closed iterator, got %t %v", ok, it.Err())
+	}
+
+	// the last hardened address number ends the iteration
+	it = w.Iterator(context.Background(), 2, External, hardened-1)
+	if index, _, ok := it.Next(); !ok || index != hardened-1 {
+		t.Errorf("Got:%d %t, expected:%d", index, ok, hardened-1)
+	}
+
+	if _, _, ok := it.Next(); ok || it.Err() != nil {
+		t.Errorf("Expected the end of the index space, got %t %v", ok, it.Err())
+	}
+
+	wo, _ := w.Neuter(2)
+	if _, _, ok := wo.Iterator(context.Background(), 2, External, 0).Next(); ok {
+		t.Errorf("Expected no addresses from a watch-only wallet")
+	}
+}
+
+func TestIteratorCancel(t *testing.T) {
+	w := testWallet(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := w.Iterator(ctx, 0, External, 0)
+
+	for i := 0; i < 5; i++ {
+		if _, _, ok := it.Next(); !ok {
+			t.Fatalf("Next %v", it.Err())
+		}
+	}
+
+	cancel()
+
+	if _, _, ok := it.Next(); ok || !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %t %v", ok, it.Err())
+	}
+
+	// the wallet is not affected by the cancelled iterator
+	it = w.Iterator(context.Background(), 0, External, 0)
+	defer it.Close()
+
+	if _, _, ok := it.Next(); !ok {
+		t.Errorf("Next %v", it.Err())
+	}
+}
+
+func TestIteratorConcurrent(t *testing.T) {
+	w := testWallet(t)
+
+	var wg sync.WaitGroup
+
+	for g := uint32(0); g < 4; g++ {
+		wg.Add(1)
+
+		go func(start uint32) {
+			defer wg.Done()
+
+			it := w.Iterator(context.Background(), 1, External, start)
+			defer it.Close()
+
+			for i := start; i < start+5; i++ {
+				index, addr, _ := it.Next()
+				if exp, _, _, _ := w.Address(1, External, i); index != i || !bytes.Equal(addr, exp) {
+					t.Errorf("Got:%d %x, expected:%d %x", index, addr, i, exp)
+				}
+			}
+		}(g * 3)
+	}
+
+	wg.Wait()
+}
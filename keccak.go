@@ -0,0 +1,21 @@
+package hd
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"golang.org/x/crypto/sha3"
+)
+
+// keccak256 returns the legacy Keccak-256 hash of the concatenated data, as used by Ethereum.
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+
+	return h.Sum(nil)
+}
+
+// ethAddress returns the Ethereum address of pub: the last 20 bytes of the keccak of its uncompressed encoding.
+func ethAddress(pub *btcec.PublicKey) []byte {
+	return keccak256(pub.SerializeUncompressed()[1:])[12:]
+}
@@ -0,0 +1,39 @@
+package hd
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// TestKeccak256 checks the internal keccak-256 and address encoding against known answers. They are cross-checked
+// against go-ethereum for random keys in package gethx.
+func TestKeccak256(t *testing.T) {
+	for _, tt := range []struct {
+		data [][]byte
+		hash string
+	}{
+		{nil, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{[][]byte{[]byte("abc")}, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		{[][]byte{[]byte("a"), []byte("bc")}, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	} {
+		if h := hex.EncodeToString(keccak256(tt.data...)); h != tt.hash {
+			t.Errorf("Keccak does not match. Got:%s, expected:%s", h, tt.hash)
+		}
+	}
+
+	// the addresses of the private keys 1 and 2
+	for i, exp := range []string{"7e5f4552091a69125d5dfcb7b8c2659029395bdf", "2b5ad5c4795c026514f8317c7a215e218dccd6cf"} {
+		prv, _ := btcec.PrivKeyFromBytes([]byte{byte(i + 1)})
+		if addr := hex.EncodeToString(ethAddress(prv.PubKey())); addr != exp {
+			t.Errorf("Address %d does not match. Got:%s, expected:%s", i+1, addr, exp)
+		}
+	}
+}
+
+// testEthAddress returns the Ethereum address of the public key of prv, computed independently of coinKeys.
+func testEthAddress(prv *ecdsa.PrivateKey) []byte {
+	return keccak256(prv.X.FillBytes(make([]byte, 32)), prv.Y.FillBytes(make([]byte, 32)))[12:]
+}
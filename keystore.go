@@ -0,0 +1,289 @@
+package hd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// StandardScryptN and StandardScryptP are the scrypt parameters ExportKeystore uses by default, as geth does.
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	// LightScryptN and LightScryptP are cheaper scrypt parameters, for tests or constrained devices.
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	keystoreVersion = 3
+	scryptR         = 8
+	scryptDKLen     = 32
+	// caps on the KDF parameters of imported keystores, which may come from untrusted users: scrypt memory, r*N, and
+	// CPU, r*N*P, up to those of the standard parameters, which also admits LightScryptN and LightScryptP
+	maxKeystoreScryptMem = scryptR * StandardScryptN
+	maxKeystoreScryptCPU = scryptR * StandardScryptN * StandardScryptP
+	maxKeystoreDKLen     = 64
+	maxPBKDF2Iterations  = 1 << 22 // geth writes 2^18
+)
+
+var (
+	// ErrInvalidKeystore will be reported for keystore files that cannot be parsed or are not supported.
+	ErrInvalidKeystore error = errors.New("hd: invalid keystore")
+	// ErrInvalidPassword will be reported when the MAC of a keystore or the password check of a wallet file does not
+	// match, usually as the password is wrong.
+	ErrInvalidPassword error = errors.New("hd: could not decrypt keystore with given password")
+)
+
+// KeystoreOption configures ExportKeystore.
+type KeystoreOption func(*keystoreParams)
+
+type keystoreParams struct {
+	n, p int
+}
+
+// WithScryptParams sets the scrypt N and P parameters of the keystore, e.g. LightScryptN and LightScryptP.
+func WithScryptParams(n, p int) KeystoreOption {
+	return func(kp *keystoreParams) {
+		kp.n, kp.p = n, p
+	}
+}
+
+// keystoreJSON is the Web3 Secret Storage (keystore V3) file format.
+type keystoreJSON struct {
+	Address string         `json:"address"`
+	Crypto  keystoreCrypto `json:"crypto"`
+	ID      string         `json:"id"`
+	Version int            `json:"version"`
+}
+
+type keystoreCrypto struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams keystoreCipherParams   `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type keystoreCipherParams struct {
+	IV string `json:"iv"`
+}
+
+// ExportKeystore encrypts the private key of the address generated by Address for 'wallet', flg and address number
+// with password, as a keystore V3 JSON file: scrypt KDF, AES-128-CTR and keccak-256 MAC. Scrypt parameters costing
+// more than StandardScryptN and StandardScryptP are refused with ErrInvalidKeystore, as ImportKeystore would.
+func (w *HdWallet) ExportKeystore(wallet uint32, flg uint8, addrNum uint32, password string,
+	opts ...KeystoreOption,
+) ([]byte, error) {
+	kp := keystoreParams{n: StandardScryptN, p: StandardScryptP}
+	for _, opt := range opts {
+		opt(&kp)
+	}
+
+	// write only keystores that ImportKeystore reads back
+	if err := checkKeystoreScrypt(kp.n, scryptR, kp.p); err != nil {
+		return nil, err
+	}
+
+	_, key, _, err := w.Address(wallet, flg, addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	// keystores hold Ethereum addresses, whatever the coin of the wallet
+	prv, _ := btcec.PrivKeyFromBytes(key)
+	addr := ethAddress(prv.PubKey())
+	prv.Zero()
+
+	// salt, IV and the 16 bytes of the UUID
+	rnd := make([]byte, 32+aes.BlockSize+16)
+	if _, err = rand.Read(rnd); err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	salt, iv, id := rnd[:32], rnd[32:32+aes.BlockSize], rnd[32+aes.BlockSize:]
+
+	derived, err := scrypt.Key([]byte(password), salt, kp.n, scryptR, kp.p, scryptDKLen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeystore, err.Error())
+	}
+	defer zero(derived)
+
+	ct, err := aesCTR(derived[:16], iv, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// random UUID, version 4
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return json.Marshal(keystoreJSON{
+		Address: hex.EncodeToString(addr),
+		Crypto: keystoreCrypto{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(ct),
+			CipherParams: keystoreCipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: map[string]interface{}{
+				"n": kp.n, "r": scryptR, "p": kp.p, "dklen": scryptDKLen, "salt": hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(keccak256(derived[16:32], ct)),
+		},
+		ID:      fmt.Sprintf("%x-%x-%x-%x-%x", id[:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: keystoreVersion,
+	})
+}
+
+// ImportKeystore decrypts a keystore V3 JSON file with password. Both the scrypt and pbkdf2 KDFs are supported. A
+// wrong password returns ErrInvalidPassword. Keystores costing more than StandardScryptN and StandardScryptP to
+// decrypt are refused with ErrInvalidKeystore, before running the KDF.
+func ImportKeystore(data []byte, password string) (ecdsa.PrivateKey, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return ecdsa.PrivateKey{}, fmt.Errorf("%w: %s", ErrInvalidKeystore, err.Error())
+	}
+
+	if ks.Version != keystoreVersion || ks.Crypto.Cipher != "aes-128-ctr" {
+		return ecdsa.PrivateKey{}, fmt.Errorf("%w: version %d, cipher %s", ErrInvalidKeystore, ks.Version,
+			ks.Crypto.Cipher)
+	}
+
+	var fields [3][]byte
+
+	for i, s := range []string{ks.Crypto.MAC, ks.Crypto.CipherParams.IV, ks.Crypto.CipherText} {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return ecdsa.PrivateKey{}, fmt.Errorf("%w: %s", ErrInvalidKeystore, err.Error())
+		}
+
+		fields[i] = b
+	}
+
+	mac, iv, ct := fields[0], fields[1], fields[2]
+
+	derived, err := keystoreKDF(ks.Crypto, password)
+	if err != nil {
+		return ecdsa.PrivateKey{}, err
+	}
+	defer zero(derived)
+
+	if !hmac.Equal(keccak256(derived[16:32], ct), mac) {
+		return ecdsa.PrivateKey{}, ErrInvalidPassword
+	}
+
+	key, err := aesCTR(derived[:16], iv, ct)
+	if err != nil {
+		return ecdsa.PrivateKey{}, err
+	}
+	defer zero(key)
+
+	// some old keystore files hold keys with the leading zeros stripped, which PrivKeyFromBytes reads as the same
+	// number, but longer keys are not valid
+	if len(key) > btcec.PrivKeyBytesLen {
+		return ecdsa.PrivateKey{}, fmt.Errorf("%w: key length %d", ErrInvalidKeystore, len(key))
+	}
+
+	prv, _ := btcec.PrivKeyFromBytes(key)
+
+	if addr, err := hex.DecodeString(ks.Address); err == nil && len(addr) != 0 &&
+		!bytes.Equal(addr, ethAddress(prv.PubKey())) {
+		return ecdsa.PrivateKey{}, fmt.Errorf("%w: address does not match key", ErrInvalidKeystore)
+	}
+
+	return *prv.ToECDSA(), nil
+}
+
+// keystoreKDF returns the key derived from password with the KDF of the keystore.
+func keystoreKDF(c keystoreCrypto, password string) ([]byte, error) {
+	salt, err := hex.DecodeString(kdfString(c.KDFParams, "salt"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeystore, err.Error())
+	}
+
+	dkLen := kdfInt(c.KDFParams, "dklen")
+	if dkLen < scryptDKLen || dkLen > maxKeystoreDKLen {
+		return nil, fmt.Errorf("%w: dklen %d", ErrInvalidKeystore, dkLen)
+	}
+
+	switch c.KDF {
+	case "scrypt":
+		// the parameters come from the file, bound them so that it cannot exhaust memory or time
+		n, r, p := kdfInt(c.KDFParams, "n"), kdfInt(c.KDFParams, "r"), kdfInt(c.KDFParams, "p")
+		if err := checkKeystoreScrypt(n, r, p); err != nil {
+			return nil, err
+		}
+
+		derived, err := scrypt.Key([]byte(password), salt, n, r, p, dkLen)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidKeystore, err.Error())
+		}
+
+		return derived, nil
+	case "pbkdf2":
+		if prf := kdfString(c.KDFParams, "prf"); prf != "hmac-sha256" {
+			return nil, fmt.Errorf("%w: prf %s", ErrInvalidKeystore, prf)
+		}
+
+		iter := kdfInt(c.KDFParams, "c")
+		if iter < 1 || iter > maxPBKDF2Iterations {
+			return nil, fmt.Errorf("%w: pbkdf2 iterations out of range", ErrInvalidKeystore)
+		}
+
+		return pbkdf2.Key([]byte(password), salt, iter, dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("%w: kdf %s", ErrInvalidKeystore, c.KDF)
+	}
+}
+
+// checkKeystoreScrypt reports ErrInvalidKeystore if the scrypt parameters of a keystore are above the caps on imports.
+func checkKeystoreScrypt(n, r, p int) error {
+	if n < 2 || r < 1 || p < 1 || r > scryptR || n > maxKeystoreScryptMem/r || p > maxKeystoreScryptCPU/(r*n) {
+		return fmt.Errorf("%w: scrypt parameters out of range", ErrInvalidKeystore)
+	}
+
+	return nil
+}
+
+// kdfInt returns the integer parameter name, or 0 if it is missing.
+func kdfInt(params map[string]interface{}, name string) int {
+	f, _ := params[name].(float64)
+
+	return int(f)
+}
+
+// kdfString returns the string parameter name, or "" if it is missing.
+func kdfString(params map[string]interface{}, name string) string {
+	s, _ := params[name].(string)
+
+	return s
+}
+
+// aesCTR encrypts or decrypts in with AES-CTR.
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("%w: iv length %d", ErrInvalidKeystore, len(iv))
+	}
+
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+
+	return out, nil
+}
@@ -0,0 +1,175 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestKeystore(t *testing.T) {
+	w := testWallet(t)
+
+	data, err := w.ExportKeystore(2, External, 1, "secret", WithScryptParams(LightScryptN, LightScryptP))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ks map[string]interface{}
+	_ = json.Unmarshal(data, &ks)
+
+	if ks["address"] != "f4cefc8d1afaa51d5a5e7f57d214b60429ca4378" {
+		t.Errorf("Address does not match. Got:%v", ks["address"])
+	}
+
+	// round trip
+	prv, err := ImportKeystore(data, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, key, _, _ := w.Address(2, External, 1)
+	if got := prv.D.FillBytes(make([]byte, 32)); !bytes.Equal(got, key) {
+		t.Errorf("Key does not match. Got:%x, expected:%x", got, key)
+	}
+
+	if _, err = ImportKeystore(data, "wrong"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Expected ErrInvalidPassword, got %v", err)
+	}
+
+	for _, tt := range []struct {
+		data []byte
+		err  error
+	}{
+		{[]byte("{"), ErrInvalidKeystore},
+		{[]byte(`{"version":1}`), ErrInvalidKeystore},
+		{bytes.Replace(data, []byte(`"scrypt"`), []byte(`"argon2"`), 1), ErrInvalidKeystore},
+	} {
+		if _, err = ImportKeystore(tt.data, "secret"); !errors.Is(err, tt.err) {
+			t.Errorf("Expected %v, got %v", tt.err, err)
+		}
+	}
+}
+
+func TestKeystoreBTC(t *testing.T) {
+	seed, _ := hex.DecodeString(testSeed)
+
+	w, err := InitForCoin(seed, CoinBTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := w.ExportKeystore(0, External, 0, "secret", WithScryptParams(LightScryptN, LightScryptP))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the keystore holds the Ethereum address of the key, so it imports back
+	prv, err := ImportKeystore(data, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, key, _, _ := w.Address(0, External, 0); !bytes.Equal(prv.D.FillBytes(make([]byte, 32)), key) {
+		t.Errorf("Key does not match. Got:%x, expected:%x", prv.D, key)
+	}
+}
+
+func TestImportKeystoreBounds(t *testing.T) {
+	w := testWallet(t)
+
+	data, err := w.ExportKeystore(2, External, 1, "secret", WithScryptParams(LightScryptN, LightScryptP))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pbkdf2 := []byte(`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},` +
+		`"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2",` +
+		`"kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256",` +
+		`"salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},` +
+		`"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},` +
+		`"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`)
+
+	// crafted parameters are refused before running the KDF
+	for _, tt := range []struct {
+		data     []byte
+		old, new string
+	}{
+		{data, `"n":4096`, `"n":1073741824`},
+		{data, `"n":4096`, `"n":-4096`},
+		{data, `"n":4096`, `"n":1048576`},
+		{data, `"p":6`, `"p":256`},
+		{data, `"p":6`, `"p":65`},
+		{data, `"r":8`, `"r":1048576`},
+		{data, `"p":6`, `"p":0`},
+		{data, `"dklen":32`, `"dklen":1073741824`},
+		{pbkdf2, `"c":262144`, `"c":1099511627776`},
+		{pbkdf2, `"c":262144`, `"c":0`},
+	} {
+		crafted := bytes.Replace(tt.data, []byte(tt.old), []byte(tt.new), 1)
+		if bytes.Equal(crafted, tt.data) {
+			t.Fatalf("%s not in keystore", tt.old)
+		}
+
+		if _, err = ImportKeystore(crafted, "secret"); !errors.Is(err, ErrInvalidKeystore) {
+			t.Errorf("%s: expected ErrInvalidKeystore, got %v", tt.new, err)
+		}
+	}
+
+	// keystores that could not be imported are not exported either
+	for _, np := range [][2]int{{1 << 20, 1}, {LightScryptN, 65}, {LightScryptN, 0}} {
+		if _, err = w.ExportKeystore(2, External, 1, "secret", WithScryptParams(np[0], np[1])); !errors.Is(err,
+			ErrInvalidKeystore) {
+			t.Errorf("N %d, P %d: expected ErrInvalidKeystore, got %v", np[0], np[1], err)
+		}
+	}
+}
+
+func TestImportKeystoreVectors(t *testing.T) {
+	// go-ethereum accounts/keystore/testdata/v3_test_vector.json
+	tests := []struct {
+		json, password, priv string
+	}{
+		{
+			`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},` +
+				`"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt",` +
+				`"kdfparams":{"dklen":32,"n":262144,"r":1,"p":8,` +
+				`"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},` +
+				`"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},` +
+				`"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+			"testpassword", "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d",
+		},
+		{
+			`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},` +
+				`"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2",` +
+				`"kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256",` +
+				`"salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},` +
+				`"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},` +
+				`"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+			"testpassword", "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d",
+		},
+		{
+			`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"e0c41130a323adc1446fc82f724bca2f"},` +
+				`"ciphertext":"9517cd5bdbe69076f9bf5057248c6c050141e970efa36ce53692d5d59a3984","kdf":"scrypt",` +
+				`"kdfparams":{"dklen":32,"n":2,"r":8,"p":1,` +
+				`"salt":"711f816911c92d649fb4c84b047915679933555030b3552c1212609b38208c63"},` +
+				`"mac":"d5e116151c6aa71470e67a7d42c9620c75c4d23229847dcc127794f0732b0db5"},` +
+				`"id":"fecfc4ce-e956-48fd-953b-30f8b52ed66c","version":3}`,
+			"foo", "00fa7b3db73dc7dfdf8c5fbdb796d741e4488628c41fc4febd9160a866ba0f35",
+		},
+	}
+
+	for i, tt := range tests {
+		prv, err := ImportKeystore([]byte(tt.json), tt.password)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+
+			continue
+		}
+
+		if got := hex.EncodeToString(prv.D.FillBytes(make([]byte, 32))); got != tt.priv {
+			t.Errorf("%d: key does not match. Got:%s, expected:%s", i, got, tt.priv)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package hd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed" // english wordlist
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	seedIterations = 2048 // PBKDF2 rounds per BIP39
+	seedLen        = 64
+	wordBits       = 11 // each word encodes 11 bits of entropy+checksum
+)
+
+var (
+	// ErrInvalidWordCount will be reported for mnemonics that do not have 12, 15, 18, 21 or 24 words.
+	ErrInvalidWordCount error = errors.New("hd: invalid number of words in mnemonic")
+	// ErrUnknownWord will be reported for mnemonic words that are not in the BIP39 English wordlist.
+	ErrUnknownWord error = errors.New("hd: word not in wordlist")
+	// ErrInvalidChecksum will be reported when the checksum of a mnemonic does not match its words.
+	ErrInvalidChecksum error = errors.New("hd: invalid mnemonic checksum")
+	// ErrInvalidEntropy will be reported for mnemonic entropy that is not 128 to 256 bits long in steps of 32.
+	ErrInvalidEntropy error = errors.New("hd: invalid mnemonic entropy length")
+)
+
+// WordError reports the first word of a mnemonic that is not in the wordlist. It matches ErrUnknownWord with
+// errors.Is.
+type WordError struct {
+	Position int // 1-based position of the word in the mnemonic
+	Word     string
+}
+
+func (e *WordError) Error() string {
+	return fmt.Sprintf("%s: %q at position %d", ErrUnknownWord, e.Word, e.Position)
+}
+
+// Unwrap returns ErrUnknownWord.
+func (e *WordError) Unwrap() error {
+	return ErrUnknownWord
+}
+
+//go:embed english.txt
+var englishTxt string //nolint:gochecknoglobals // embedded BIP39 wordlist
+
+var (
+	wordlistOnce sync.Once //nolint:gochecknoglobals // guards wordlist
+	wordlist     []string  //nolint:gochecknoglobals // parsed once from englishTxt
+)
+
+// englishWords returns the BIP39 English wordlist, which is sorted.
+func englishWords() []string {
+	wordlistOnce.Do(func() {
+		wordlist = strings.Fields(englishTxt)
+	})
+
+	return wordlist
+}
+
+// NewMnemonic generates a BIP39 mnemonic from entropyBits (128, 160, 192, 224 or 256) of random entropy, giving 12,
+// 15, 18, 21 or 24 words respectively.
+func NewMnemonic(entropyBits int) (string, error) {
+	if entropyBits < 128 || entropyBits > 256 || entropyBits%32 != 0 {
+		return "", fmt.Errorf("%w: %d bits", ErrInvalidEntropy, entropyBits)
+	}
+
+	entropy := make([]byte, entropyBits/8)
+	defer zero(entropy)
+
+	if _, err := rand.Read(entropy); err != nil {
+		return "", fmt.Errorf("%s: %w", ErrInternal, err)
+	}
+
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy encodes 16 to 32 bytes of entropy, in steps of 4, as a BIP39 mnemonic.
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("%w: %d bytes", ErrInvalidEntropy, len(entropy))
+	}
+
+	h := sha256.Sum256(entropy)
+	bits := append(append(make([]byte, 0, len(entropy)+1), entropy...), h[0])
+	defer zero(bits)
+
+	words := make([]string, (len(entropy)*8+len(entropy)/4)/wordBits)
+	for i := range words {
+		var idx int
+
+		for j := 0; j < wordBits; j++ {
+			bit := i*wordBits + j
+			idx = idx<<1 | int(bits[bit/8]>>(7-bit%8)&1)
+		}
+
+		words[i] = englishWords()[idx]
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// SeedFromMnemonic validates the mnemonic and derives the 64-byte BIP39 seed for it and the passphrase, which can be
+// empty. Both are NFKD normalized as the BIP requires.
+func SeedFromMnemonic(mnemonic, passphrase string) ([]byte, error) {
+	entropy, err := mnemonicEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	zero(entropy)
+
+	words := strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+
+	return pbkdf2.Key([]byte(words), []byte("mnemonic"+norm.NFKD.String(passphrase)), seedIterations, seedLen,
+		sha512.New), nil
+}
+
+// ValidateMnemonic checks the mnemonic has a valid number of words, all of them in the English wordlist, and a valid
+// checksum. Words are NFKD normalized and can be separated by any whitespace. The first unknown word is reported as
+// a WordError.
+func ValidateMnemonic(mnemonic string) error {
+	entropy, err := mnemonicEntropy(mnemonic)
+	if err != nil {
+		return err
+	}
+
+	zero(entropy)
+
+	return nil
+}
+
+// SuggestWords returns the words of the English wordlist starting with prefix, in order. As BIP39 words are unique
+// in their first four letters, a prefix of four letters or more matches one word at most. An empty prefix matches
+// nothing.
+func SuggestWords(prefix string) []string {
+	prefix = strings.ToLower(strings.TrimSpace(norm.NFKD.String(prefix)))
+	if prefix == "" {
+		return nil
+	}
+
+	list := englishWords()
+
+	var words []string
+
+	for i := sort.SearchStrings(list, prefix); i < len(list) && strings.HasPrefix(list[i], prefix); i++ {
+		words = append(words, list[i])
+	}
+
+	return words
+}
+
+// mnemonicEntropy decodes the mnemonic words into its entropy, verifying the checksum.
+func mnemonicEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrInvalidWordCount, len(words))
+	}
+
+	list := englishWords()
+	bits := make([]byte, (len(words)*wordBits+7)/8)
+
+	for i, w := range words {
+		idx := sort.SearchStrings(list, w)
+		if idx == len(list) || list[idx] != w {
+			return nil, &WordError{Position: i + 1, Word: w}
+		}
+
+		for j := 0; j < wordBits; j++ {
+			if idx>>(wordBits-1-j)&1 == 1 {
+				bit := i*wordBits + j
+				bits[bit/8] |= 1 << (7 - bit%8)
+			}
+		}
+	}
+
+	// the checksum is the first ENT/32 bits of the entropy's SHA-256, placed right after it
+	entBytes := len(words) * wordBits * 32 / 33 / 8
+	csBits := len(words) * wordBits / 33
+	entropy := bits[:entBytes]
+
+	if h := sha256.Sum256(entropy); h[0]>>(8-csBits) != bits[entBytes]>>(8-csBits) {
+		zero(bits)
+
+		return nil, ErrInvalidChecksum
+	}
+
+	return entropy, nil
+}
@@ -0,0 +1,66 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// ErrNetworkMismatch will be reported when restoring a key of another network than the one of the wallet.
+var ErrNetworkMismatch error = errors.New("hd: key belongs to another network")
+
+// WithNetwork sets the network whose version bytes are used to serialize the keys of the wallet, e.g.
+// &chaincfg.TestNet3Params for tprv/tpub keys. Wallets use chaincfg.MainNetParams, xprv/xpub, by default. The network
+// also encodes the addresses and WIF keys of Bitcoin wallets, e.g. &chaincfg.RegressionNetParams for regtest.
+func WithNetwork(net *chaincfg.Params) Option {
+	return func(w *HdWallet) {
+		w.net = net
+	}
+}
+
+// Network returns the network of the serialized keys of the wallet.
+func (w *HdWallet) Network() *chaincfg.Params {
+	if w.net == nil {
+		return &chaincfg.MainNetParams
+	}
+
+	return w.net
+}
+
+// checkNetwork checks version are the private key version bytes of the network of the wallet. If no network was
+// set and version is the one of testnet, it is set to testnet; mainnet keys leave it unset, as it is the default, so
+// that the Bitcoin addresses of the wallet keep to the network of its coin.
+func (w *HdWallet) checkNetwork(version []byte) error {
+	if w.net != nil {
+		if !bytes.Equal(version, w.net.HDPrivateKeyID[:]) {
+			return fmt.Errorf("%w: version %x, expected %x for %s", ErrNetworkMismatch, version,
+				w.net.HDPrivateKeyID, w.net.Name)
+		}
+
+		return nil
+	}
+
+	switch {
+	case bytes.Equal(version, chaincfg.MainNetParams.HDPrivateKeyID[:]):
+		return nil
+	case bytes.Equal(version, chaincfg.TestNet3Params.HDPrivateKeyID[:]):
+		w.net = &chaincfg.TestNet3Params
+
+		return nil
+	default:
+		return fmt.Errorf("%w: version %x", ErrInvalidXprv, version)
+	}
+}
+
+// publicNetwork returns the network, mainnet or testnet, whose public key version bytes are version, nil if none.
+func publicNetwork(version []byte) *chaincfg.Params {
+	for _, net := range []*chaincfg.Params{&chaincfg.MainNetParams, &chaincfg.TestNet3Params} {
+		if bytes.Equal(version, net.HDPublicKeyID[:]) {
+			return net
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestWithNetwork(t *testing.T) {
+	// BIP32 testnet vector 1 root with testnet version bytes
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	master, _ := getHdMaster(seed, chaincfg.TestNet3Params.HDPrivateKeyID[:], masterKeySecp256k1)
+	if exp := "tprv8ZgxMBicQKsPeDgjzdC36fs6bMjGApWDNLR9erAXMs5skhMv36j9MV5ecvfavji5khqjWaWSFhN3YcCUUdiKH6isR4Pwy3U5y5" +
+		"egddBr16m"; master.String() != exp {
+		t.Errorf("Master does not match. Got:%s, expected:%s", master, exp)
+	}
+
+	mainnet := testWallet(t)
+	testnet := testWallet(t, WithNetwork(&chaincfg.TestNet3Params))
+
+	if testnet.Network() != &chaincfg.TestNet3Params || mainnet.Network() != &chaincfg.MainNetParams {
+		t.Errorf("Network does not match. Got:%s and %s", testnet.Network().Name, mainnet.Network().Name)
+	}
+
+	// the network only changes the serialization
+	addr, _, _, _ := mainnet.Address(2, External, 0)
+	if taddr, _, _, _ := testnet.Address(2, External, 0); !bytes.Equal(addr, taddr) {
+		t.Errorf("Address does not match. Got:%x, expected:%x", taddr, addr)
+	}
+
+	tprv, _ := testnet.Serialize()
+	xprv, _ := mainnet.Serialize()
+	tpub, _ := testnet.ExportXpub(2)
+
+	if !strings.HasPrefix(tprv, "tprv") || !strings.HasPrefix(xprv, "xprv") || !strings.HasPrefix(tpub, "tpub") {
+		t.Errorf("Version bytes do not match. Got:%s %s %s", tprv, xprv, tpub)
+	}
+
+	if k, _ := hdkeychain.NewKeyFromString(tprv); !k.IsForNet(&chaincfg.TestNet3Params) {
+		t.Errorf("Key is not for testnet")
+	}
+
+	// restoring keeps the network of the key, unless another one is required
+	r, err := Restore(tprv)
+	if err != nil || r.Network() != &chaincfg.TestNet3Params {
+		t.Fatalf("Restore tprv. Got:%v, err:%v", r, err)
+	}
+
+	if s, _ := r.Serialize(); s != tprv {
+		t.Errorf("Serialize does not round trip. Got:%s, expected:%s", s, tprv)
+	}
+
+	if _, err = Restore(tprv, WithNetwork(&chaincfg.TestNet3Params)); err != nil {
+		t.Errorf("Restore tprv on testnet: %v", err)
+	}
+
+	if _, err = Restore(tprv, WithNetwork(&chaincfg.MainNetParams)); !errors.Is(err, ErrNetworkMismatch) {
+		t.Errorf("Expected ErrNetworkMismatch, got %v", err)
+	}
+
+	if _, err = Restore(xprv, WithNetwork(&chaincfg.TestNet3Params)); !errors.Is(err, ErrNetworkMismatch) {
+		t.Errorf("Expected ErrNetworkMismatch, got %v", err)
+	}
+
+	// so does loading an xpub
+	wo, err := FromXpub(tpub)
+	if err != nil || wo.Network() != &chaincfg.TestNet3Params {
+		t.Fatalf("FromXpub tpub. Got:%v, err:%v", wo, err)
+	}
+
+	if s, _ := wo.ExportXpub(2); s != tpub {
+		t.Errorf("ExportXpub does not round trip. Got:%s, expected:%s", s, tpub)
+	}
+}
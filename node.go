@@ -0,0 +1,173 @@
+package hd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// PathStep is a step of a derivation, a child index below 2^31 which is hardened or not.
+type PathStep struct {
+	Index    uint32
+	Hardened bool
+}
+
+// child returns the child index of the step.
+func (s PathStep) child() (uint32, error) {
+	if s.Index >= hardened {
+		return 0, ErrIndexOutOfRange
+	}
+
+	if s.Hardened {
+		return hardened + s.Index, nil
+	}
+
+	return s.Index, nil
+}
+
+// Node is a key derived with DeriveChild. It records its path from the master key, so it can always report it.
+type Node struct {
+	w      *HdWallet
+	key    *hdkeychain.ExtendedKey
+	path   Path
+	mu     sync.RWMutex // guards closed against in-flight uses of key
+	closed bool
+}
+
+// Path returns the path the wallet is rooted at, m/44'/coin'.
+func (w *HdWallet) Path() string {
+	return w.root().String()
+}
+
+// Depth returns the depth of the root of the wallet, 2.
+func (w *HdWallet) Depth() uint8 {
+	return uint8(len(w.root()))
+}
+
+// DeriveChild derives step from the root of the wallet. As with DeriveFromPath the step, the account, must be
+// hardened; watch-only wallets return the public key of the accounts they hold.
+func (w *HdWallet) DeriveChild(step PathStep) (*Node, error) {
+	i, err := step.child()
+	if err != nil {
+		return nil, err
+	}
+
+	p := append(w.root(), i)
+
+	k, err := w.derivePath(p, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{w: w, key: k, path: p}, nil
+}
+
+// Path returns the path of the node from the master key, e.g. "m/44'/60'/0'/0".
+func (n *Node) Path() string {
+	return n.path.String()
+}
+
+// Depth returns the depth of the node, the number of components of its path.
+func (n *Node) Depth() uint8 {
+	return uint8(len(n.path))
+}
+
+// DeriveChild derives step from the node. The limits of the wallet apply as with DeriveFromPath, and a node with a
+// public key can only derive non-hardened steps, else ErrWatchOnly is returned.
+func (n *Node) DeriveChild(step PathStep) (*Node, error) {
+	i, err := step.child()
+	if err != nil {
+		return nil, err
+	}
+
+	p := append(append(make(Path, 0, len(n.path)+1), n.path...), i)
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.closed {
+		return nil, ErrClosed
+	}
+
+	if step.Hardened && !n.key.IsPrivate() {
+		return nil, fmt.Errorf("%w: hardened step of %s", ErrWatchOnly, p)
+	}
+
+	n.w.mu.RLock()
+	defer n.w.mu.RUnlock()
+
+	if n.w.closed {
+		return nil, ErrClosed
+	}
+
+	if err = n.w.policy.allowDerivation(p[2]-hardened, i&^hardened); err != nil {
+		return nil, err
+	}
+
+	k, err := deriveChild(n.key, p, len(p)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{w: n.w, key: k, path: p}, nil
+}
+
+// Address returns the address of the node, encoded for the coin of the wallet as Address does.
+func (n *Node) Address() ([]byte, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.closed {
+		return nil, ErrClosed
+	}
+
+	pub, err := n.key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return coinAddress(n.w.coin, pub), nil
+}
+
+// PrivateKey returns the private key bytes of the node, or ErrWatchOnly if it only holds the public key.
+func (n *Node) PrivateKey() ([]byte, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.closed {
+		return nil, ErrClosed
+	}
+
+	if !n.key.IsPrivate() {
+		return nil, ErrWatchOnly
+	}
+
+	_, key, _ := coinKeys(n.w.coin, n.key)
+
+	return key, nil
+}
+
+// Zero wipes the key of the node, like Close does for the wallet. Afterwards its methods return ErrClosed, and zeroing
+// it again is a no-op.
+func (n *Node) Zero() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return
+	}
+
+	n.closed = true
+	n.key.Zero()
+}
+
+// root returns the path of the branch of the wallet.
+func (w *HdWallet) root() Path {
+	return Path{hardened + purpose, hardened + w.coin}
+}
+
+// addressPath returns the path of the address child of 'wallet' and flg.
+func (w *HdWallet) addressPath(wallet uint32, flg uint8, child uint32) Path {
+	return append(w.root(), hardened+wallet, uint32(flg&Change), child)
+}
@@ -0,0 +1,113 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDeriveChild(t *testing.T) {
+	w := testWallet(t)
+
+	if w.Path() != "m/44'/60'" || w.Depth() != 2 {
+		t.Errorf("Got:%s %d, expected:m/44'/60' 2", w.Path(), w.Depth())
+	}
+
+	acct, err := w.DeriveChild(PathStep{Index: 2, Hardened: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	br, _ := acct.DeriveChild(PathStep{Index: uint32(External)})
+
+	leaf, err := br.DeriveChild(PathStep{Index: 3, Hardened: true})
+	if err != nil || leaf.Path() != "m/44'/60'/2'/0/3'" || leaf.Depth() != 5 {
+		t.Fatalf("Got:%s %d, err:%v", leaf.Path(), leaf.Depth(), err)
+	}
+
+	// nodes derive the same keys as Address
+	expAddr, expKey, _, _ := w.Address(2, External, 3)
+	addr, _ := leaf.Address()
+	key, err := leaf.PrivateKey()
+
+	if err != nil || !bytes.Equal(addr, expAddr) || !bytes.Equal(key, expKey) {
+		t.Errorf("Got:%x %x, expected:%x %x, err:%v", addr, key, expAddr, expKey, err)
+	}
+
+	leaf.Zero()
+	leaf.Zero()
+
+	if _, err = leaf.Address(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Address of a zeroed node. Expected ErrClosed, got %v", err)
+	}
+
+	if _, err = leaf.PrivateKey(); !errors.Is(err, ErrClosed) {
+		t.Errorf("PrivateKey of a zeroed node. Expected ErrClosed, got %v", err)
+	}
+
+	if _, err = leaf.DeriveChild(PathStep{Index: 0}); !errors.Is(err, ErrClosed) {
+		t.Errorf("DeriveChild of a zeroed node. Expected ErrClosed, got %v", err)
+	}
+
+	if leaf.Path() != "m/44'/60'/2'/0/3'" || leaf.Depth() != 5 {
+		t.Errorf("Zeroed node. Got:%s %d", leaf.Path(), leaf.Depth())
+	}
+
+	if _, err = w.DeriveChild(PathStep{Index: 2}); !errors.Is(err, ErrPathNotInWallet) {
+		t.Errorf("Expected ErrPathNotInWallet, got %v", err)
+	}
+
+	if _, err = br.DeriveChild(PathStep{Index: hardened}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	seed, _ := hex.DecodeString(testSeed)
+	if btc, _ := InitForCoin(seed, CoinBTC); btc.Path() != "m/44'/0'" {
+		t.Errorf("Got:%s, expected:m/44'/0'", btc.Path())
+	}
+
+	w.Close()
+
+	if _, err = br.DeriveChild(PathStep{Index: 0}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+func TestDeriveChildWatchOnly(t *testing.T) {
+	w := testWallet(t, WithLimits(Limits{MaxIndex: 5}))
+
+	wo, _ := w.Neuter(2)
+
+	acct, err := wo.DeriveChild(PathStep{Index: 2, Hardened: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	br, _ := acct.DeriveChild(PathStep{Index: uint32(Change)})
+	leaf, _ := br.DeriveChild(PathStep{Index: 4})
+
+	addr, _ := leaf.Address()
+	if exp, _ := w.PublicAddress(2, Change, 4); !bytes.Equal(addr, exp) {
+		t.Errorf("Got:%x, expected:%x", addr, exp)
+	}
+
+	if _, err = leaf.PrivateKey(); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+
+	// public nodes cannot derive hardened steps, the error reports the full path
+	if _, err = br.DeriveChild(PathStep{Index: 4, Hardened: true}); !errors.Is(err, ErrWatchOnly) ||
+		!strings.Contains(err.Error(), "m/44'/60'/2'/1/4'") {
+		t.Errorf("Expected ErrWatchOnly on m/44'/60'/2'/1/4', got %v", err)
+	}
+
+	// the limits of the wallet apply to the nodes derived from it
+	nw, _ := w.DeriveChild(PathStep{Index: 2, Hardened: true})
+	nbr, _ := nw.DeriveChild(PathStep{Index: uint32(Change)})
+
+	if _, err = nbr.DeriveChild(PathStep{Index: 6}); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("Expected ErrPolicyExceeded, got %v", err)
+	}
+}
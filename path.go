@@ -0,0 +1,233 @@
+package hd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+var (
+	// ErrInvalidPath will be reported for derivation paths that cannot be parsed.
+	ErrInvalidPath error = errors.New("hd: invalid derivation path")
+	// ErrPathNotInWallet will be reported for paths outside of the m/44'/coin' branch held by the wallet.
+	ErrPathNotInWallet error = errors.New("hd: derivation path is not in the wallet branch")
+)
+
+// DerivationError reports the failure of a BIP32 derivation step, e.g. hdkeychain.ErrInvalidChild. It matches
+// ErrInternal with errors.Is, as well as its cause.
+type DerivationError struct {
+	Path      Path  // path being derived
+	Component int   // index in Path of the child that could not be derived
+	Err       error // cause
+}
+
+func (e *DerivationError) Error() string {
+	return fmt.Sprintf("hd: deriving %s failed at %s: %v", e.Path, e.Path[:e.Component+1], e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *DerivationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrInternal.
+func (e *DerivationError) Is(target error) bool {
+	return errors.Is(ErrInternal, target)
+}
+
+// Path is a BIP32 derivation path, absolute from the master key: each element is a child index, with hardened
+// indices at or above 2^31.
+type Path []uint32
+
+// ParsePath parses a derivation path such as "m/44'/60'/0'/0/5". The leading "m/" is optional, and hardened indices
+// are marked with either ', h or H. Paths are always absolute from the master key.
+func ParsePath(s string) (Path, error) {
+	s = strings.TrimPrefix(s, "m/")
+	if s == "" || s == "m" {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+
+	segs := strings.Split(s, "/")
+	p := make(Path, 0, len(segs))
+
+	for i, seg := range segs {
+		var hard uint32
+
+		if strings.HasSuffix(seg, "'") || strings.HasSuffix(seg, "h") || strings.HasSuffix(seg, "H") {
+			seg, hard = seg[:len(seg)-1], hardened
+		}
+
+		if seg == "" {
+			return nil, fmt.Errorf("%w: segment %d is empty", ErrInvalidPath, i)
+		}
+
+		n, err := strconv.ParseUint(seg, 10, 32)
+		if err != nil || uint32(n) >= hardened {
+			return nil, fmt.Errorf("%w: segment %d out of range", ErrInvalidPath, i)
+		}
+
+		p = append(p, uint32(n)+hard)
+	}
+
+	return p, nil
+}
+
+// String returns the path in the "m/44'/60'/0'/0/5" notation.
+func (p Path) String() string {
+	var b strings.Builder
+
+	b.WriteString("m")
+
+	for _, n := range p {
+		b.WriteString("/")
+
+		if n >= hardened {
+			b.WriteString(strconv.FormatUint(uint64(n-hardened), 10))
+			b.WriteString("'")
+
+			continue
+		}
+
+		b.WriteString(strconv.FormatUint(uint64(n), 10))
+	}
+
+	return b.String()
+}
+
+// DeriveFromPath returns the key at the absolute path p. The wallet only holds the m/44'/coin' branch it was
+// initialized with, so p must start with it, else ErrPathNotInWallet is returned. Watch-only wallets can only derive
+// non-hardened children of the accounts they hold.
+// The policy of the wallet is checked with the account p[2] and, below the account, the unhardened last index of p as
+// address number. Keys above the address level reach every address below them, so while the wallet has limits only
+// paths of length 5 (m/44'/coin'/account'/change/index) are allowed.
+func (w *HdWallet) DeriveFromPath(p Path) (*hdkeychain.ExtendedKey, error) {
+	return w.derivePath(p, true)
+}
+
+// derivePath derives p as DeriveFromPath does. With addrOnly false any path below the account is allowed while the
+// wallet has limits, for callers that do not hand out the chain code of the key or check the limits at every step
+// as Node does.
+func (w *HdWallet) derivePath(p Path, addrOnly bool) (*hdkeychain.ExtendedKey, error) {
+	if len(p) < 2 || p[0] != hardened+purpose || p[1] != hardened+w.coin {
+		return nil, fmt.Errorf("%w: %s", ErrPathNotInWallet, p)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil, ErrClosed
+	}
+
+	if len(p) == 2 {
+		if w.WatchOnly() {
+			return nil, ErrWatchOnly
+		}
+
+		if w.policy.limited() {
+			return nil, fmt.Errorf("%w: branch key of a wallet with limits", ErrPolicyExceeded)
+		}
+
+		return copyKey(w.key)
+	}
+
+	if p[2] < hardened {
+		return nil, fmt.Errorf("%w: account index must be hardened", ErrPathNotInWallet)
+	}
+
+	if addrOnly && len(p) != 5 && w.policy.limited() {
+		return nil, fmt.Errorf("%w: %s is not an address path of a wallet with limits", ErrPolicyExceeded, p)
+	}
+
+	var addrNum uint32
+	if len(p) > 3 {
+		addrNum = p[len(p)-1] &^ hardened
+	}
+
+	if err := w.policy.allowDerivation(p[2]-hardened, addrNum); err != nil {
+		return nil, err
+	}
+
+	k, err := w.account(p[2] - hardened)
+	if err != nil {
+		return nil, withPath(err, p)
+	}
+
+	if len(p) == 3 && w.WatchOnly() {
+		return copyKey(k)
+	}
+
+	// wipe intermediate keys, but not the account key held by a watch-only wallet
+	owned := !w.WatchOnly()
+
+	for i := 3; i < len(p); i++ {
+		next, err := deriveChild(k, p, i)
+		if owned {
+			k.Zero()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		k, owned = next, true
+	}
+
+	return k, nil
+}
+
+// AddressAtPath parses the absolute path s and returns the address and private key bytes at it, see DeriveFromPath.
+func (w *HdWallet) AddressAtPath(s string) (addr, key []byte, err error) {
+	if w.WatchOnly() {
+		return nil, nil, ErrWatchOnly
+	}
+
+	p, err := ParsePath(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k, err := w.DeriveFromPath(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer k.Zero()
+
+	addr, key, _ = w.keys(k)
+
+	return addr, key, nil
+}
+
+// deriveChild derives the child p[i] of k, reporting a failure as a DerivationError on p.
+func deriveChild(k *hdkeychain.ExtendedKey, p Path, i int) (*hdkeychain.ExtendedKey, error) {
+	child, err := k.Derive(p[i])
+	if err != nil {
+		return nil, &DerivationError{Path: p, Component: i, Err: err}
+	}
+
+	return child, nil
+}
+
+// withPath sets p as the path of err if it is a DerivationError on a prefix of p, so that it reports the whole path
+// that was being derived.
+func withPath(err error, p Path) error {
+	var de *DerivationError
+	if errors.As(err, &de) && len(de.Path) <= len(p) {
+		de.Path = p
+	}
+
+	return err
+}
+
+// copyKey returns a copy of k that does not share buffers with it, so that closing the wallet does not zero it.
+func copyKey(k *hdkeychain.ExtendedKey) (*hdkeychain.ExtendedKey, error) {
+	c, err := hdkeychain.NewKeyFromString(k.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return c, nil
+}
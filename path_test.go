@@ -0,0 +1,280 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		s    string
+		path Path
+		err  error
+	}{
+		{"m/44'/60'/0'/0/5", Path{hardened + 44, hardened + 60, hardened, 0, 5}, nil},
+		{"44h/60h/0h/0/5", Path{hardened + 44, hardened + 60, hardened, 0, 5}, nil},
+		{"m/1H/2", Path{hardened + 1, 2}, nil},
+		{"m/0/2147483647'", Path{0, hardened + 2147483647}, nil},
+		{"m", nil, ErrInvalidPath},
+		{"m/", nil, ErrInvalidPath},
+		{"m/44'//0", nil, ErrInvalidPath},
+		{"m/44'/60'/", nil, ErrInvalidPath},
+		{"m/2147483648", nil, ErrInvalidPath},
+		{"m/-1", nil, ErrInvalidPath},
+		{"m/0''", nil, ErrInvalidPath},
+		{"m/h", nil, ErrInvalidPath},
+		{"m/0x10", nil, ErrInvalidPath},
+	}
+
+	for _, tt := range tests {
+		p, err := ParsePath(tt.s)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.s, tt.err, err)
+
+			continue
+		}
+
+		if len(p) != len(tt.path) {
+			t.Errorf("%s: Got:%v, expected:%v", tt.s, p, tt.path)
+
+			continue
+		}
+
+		for i := range p {
+			if p[i] != tt.path[i] {
+				t.Errorf("%s: Got:%v, expected:%v", tt.s, p, tt.path)
+			}
+		}
+	}
+
+	if s := (Path{hardened + 44, hardened + 60, hardened, 0, 5}).String(); s != "m/44'/60'/0'/0/5" {
+		t.Errorf("Got:%s, expected:m/44'/60'/0'/0/5", s)
+	}
+}
+
+func TestAddressAtPath(t *testing.T) {
+	w := testWallet(t)
+
+	tests := []struct {
+		path string
+		key  string
+	}{
+		// MetaMask
+		{"m/44'/60'/0'/0/5", "bff00e9b700c29bfcbd967f3726dfd3ec8211966bb76b916b0037f1c79fdc682"},
+		// Ledger Live
+		{"m/44'/60'/4'/0/0", "625d1f6903f9f741ba1ef97d766307768afa286db15cda96199c4be0d5d4e675"},
+		// Ledger legacy (MEW)
+		{"m/44'/60'/0'/3", "07127e08b3c933bb77147848b79b377d59e31ff8267403bdc47c3c3abab61db2"},
+		// this package's Address scheme, see TestHdWallet
+		{"m/44'/60'/2'/0/1'", "fa7d6a67439ec17e07c10f10a4a9007e46583b5219cb909c8b474398b7216917"},
+	}
+
+	for _, tt := range tests {
+		addr, key, err := w.AddressAtPath(tt.path)
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+
+			continue
+		}
+
+		if hex.EncodeToString(key) != tt.key {
+			t.Errorf("%s: key does not match. Got:%x, expected:%s", tt.path, key, tt.key)
+		}
+
+		prv, _ := btcec.PrivKeyFromBytes(key)
+		if exp := testEthAddress(prv.ToECDSA()); !bytes.Equal(addr, exp) {
+			t.Errorf("%s: address does not match. Got:%x, expected:%x", tt.path, addr, exp)
+		}
+	}
+
+	// the paths match the Address and AddressBIP44 schemes
+	addr, _, _ := w.AddressAtPath("m/44'/60'/2'/1/7'")
+	if exp, _, _, _ := w.Address(2, Change, 7); !bytes.Equal(addr, exp) {
+		t.Errorf("Address does not match. Got:%x, expected:%x", addr, exp)
+	}
+
+	addr, _, _ = w.AddressAtPath("m/44h/60h/3h/0/9")
+	if exp, _, _, _ := w.AddressBIP44(3, External, 9); !bytes.Equal(addr, exp) {
+		t.Errorf("AddressBIP44 does not match. Got:%x, expected:%x", addr, exp)
+	}
+
+	for _, s := range []string{"m/44'/0'/0'/0/0", "m/49'/60'/0'/0/0", "m/44'", "m/44'/60'/0/0/0"} {
+		if _, _, err := w.AddressAtPath(s); !errors.Is(err, ErrPathNotInWallet) {
+			t.Errorf("%s: expected ErrPathNotInWallet, got %v", s, err)
+		}
+	}
+
+	// with limits only address paths are derived, as with DeriveFromPath
+	w = testWallet(t, WithLimits(Limits{MaxIndex: 10}))
+
+	for _, s := range []string{"m/44'/60'/0'", "m/44'/60'/0'/3"} {
+		if _, _, err := w.AddressAtPath(s); !errors.Is(err, ErrPolicyExceeded) {
+			t.Errorf("%s: expected ErrPolicyExceeded, got %v", s, err)
+		}
+	}
+
+	if _, _, err := w.AddressAtPath("m/44'/60'/0'/0/5"); err != nil {
+		t.Errorf("Expected address, got %v", err)
+	}
+}
+
+func TestDeriveFromPathWatchOnly(t *testing.T) {
+	w := testWallet(t)
+
+	wo, err := w.Neuter(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := wo.DeriveFromPath(Path{hardened + 44, hardened + 60, hardened, 0, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, _ := k.ECPubKey()
+	if addr, _ := w.PublicAddress(0, External, 5); !bytes.Equal(ethAddress(pub), addr) {
+		t.Errorf("Address does not match. Got:%x, expected:%x", ethAddress(pub), addr)
+	}
+
+	tests := []struct {
+		path Path
+		err  error
+	}{
+		{Path{hardened + 44, hardened + 60}, ErrWatchOnly},
+		{Path{hardened + 44, hardened + 60, hardened + 1, 0}, ErrAccountNotAvailable},
+		{Path{hardened + 44, hardened + 60, hardened, 0, hardened}, hdkeychain.ErrDeriveHardFromPublic},
+	}
+
+	for _, tt := range tests {
+		if _, err := wo.DeriveFromPath(tt.path); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.err, err)
+		}
+	}
+
+	// the account key returned is a copy, closing the wallet does not zero it
+	acct, _ := wo.DeriveFromPath(Path{hardened + 44, hardened + 60, hardened})
+	xpub := acct.String()
+	_ = wo.Close()
+
+	if acct.String() != xpub {
+		t.Errorf("Account key was zeroed by Close")
+	}
+
+	if _, err := wo.DeriveFromPath(Path{hardened + 44, hardened + 60, hardened}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+func TestDeriveFromPathLimits(t *testing.T) {
+	w := testWallet(t, WithLimits(Limits{AllowedAccounts: []uint32{0}}))
+
+	tests := []struct {
+		path Path
+		err  error
+	}{
+		{Path{hardened + 44, hardened + 60}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened + 7}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened + 7, 0}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened + 7, 0, 0}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened, 0}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened, 0, 0, 0}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened, 0, 0}, nil},
+	}
+
+	for _, tt := range tests {
+		if _, err := w.DeriveFromPath(tt.path); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.err, err)
+		}
+	}
+
+	// account and branch keys would derive any index past MaxIndex
+	w = testWallet(t, WithLimits(Limits{MaxIndex: 10}))
+
+	tests = []struct {
+		path Path
+		err  error
+	}{
+		{Path{hardened + 44, hardened + 60, hardened}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened, 0}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened, 1}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened, 0, 11}, ErrPolicyExceeded},
+		{Path{hardened + 44, hardened + 60, hardened, 0, 10}, nil},
+	}
+
+	for _, tt := range tests {
+		if _, err := w.DeriveFromPath(tt.path); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.err, err)
+		}
+	}
+
+	// without limits the branch key is available
+	if _, err := testWallet(t).DeriveFromPath(Path{hardened + 44, hardened + 60}); err != nil {
+		t.Errorf("Expected branch key, got %v", err)
+	}
+}
+
+func TestDerivationError(t *testing.T) {
+	w := testWallet(t)
+
+	// hdkeychain refuses keys deeper than 255
+	p := make(Path, 260)
+	copy(p, Path{hardened + 44, hardened + 60, hardened + 2})
+
+	_, err := w.DeriveFromPath(p)
+
+	var de *DerivationError
+	if !errors.As(err, &de) || de.Component != 255 || len(de.Path) != 260 {
+		t.Fatalf("Expected a DerivationError at component 255, got %v", err)
+	}
+
+	if !errors.Is(err, hdkeychain.ErrDeriveBeyondMaxDepth) || !errors.Is(err, ErrInternal) {
+		t.Errorf("DerivationError does not match its cause and ErrInternal: %v", err)
+	}
+
+	// a public branch cannot derive the hardened address numbers of Address
+	wo, _ := w.Neuter(2)
+
+	_, err = wo.derive(2, External, 17, hardened+17)
+	if !errors.As(err, &de) || de.Component != 4 || !errors.Is(err, hdkeychain.ErrDeriveHardFromPublic) {
+		t.Fatalf("Expected a DerivationError at component 4, got %v", err)
+	}
+
+	exp := "hd: deriving m/44'/60'/2'/0/17' failed at m/44'/60'/2'/0/17': " + hdkeychain.ErrDeriveHardFromPublic.Error()
+	if err.Error() != exp {
+		t.Errorf("Error does not match. Got:%s, expected:%s", err, exp)
+	}
+
+	_, err = wo.DeriveFromPath(Path{hardened + 44, hardened + 60, hardened + 2, hardened + 1, 5})
+	if !errors.As(err, &de) || de.Path.String() != "m/44'/60'/2'/1'/5" || de.Component != 3 {
+		t.Errorf("Expected a DerivationError at component 3, got %v", err)
+	}
+}
+
+func FuzzParsePath(f *testing.F) {
+	for _, s := range []string{"m/44'/60'/0'/0/5", "44h/60H/0'", "m", "m/", "m/2147483648", "m/0//1", "m/-1", "m/1''"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		p, err := ParsePath(s)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidPath) {
+				t.Fatalf("%q: expected ErrInvalidPath, got %v", s, err)
+			}
+
+			return
+		}
+
+		// the canonical form parses back to the same path
+		q, err := ParsePath(p.String())
+		if err != nil || q.String() != p.String() || len(q) != len(p) {
+			t.Errorf("%q: %s parsed back as %s, err:%v", s, p, q, err)
+		}
+	})
+}
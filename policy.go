@@ -0,0 +1,173 @@
+package hd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limits restrict what a wallet is allowed to derive. Zero values mean no limit.
+type Limits struct {
+	// MaxDerivationsPerMinute caps the number of addresses derived within a one-minute window.
+	MaxDerivationsPerMinute uint32
+	// MaxSignaturesPerMinute caps the number of signatures made within a one-minute window.
+	MaxSignaturesPerMinute uint32
+	// MaxIndex is the highest address index that can be derived.
+	MaxIndex uint32
+	// AllowedAccounts lists the wallet numbers that can be derived, all of them if empty.
+	AllowedAccounts []uint32
+}
+
+// Stats are the counters kept by the wallet's policy layer.
+type Stats struct {
+	Derivations uint64 // addresses derived
+	Signatures  uint64 // signatures made
+	Rejected    uint64 // derivations or signatures refused because a limit tripped
+}
+
+// Option configures a HdWallet on Init.
+type Option func(*HdWallet)
+
+// WithLimits enforces limits on the derivations of the wallet.
+func WithLimits(l Limits) Option {
+	return func(w *HdWallet) {
+		w.policy.set(l)
+	}
+}
+
+// SetLimits replaces the limits of the wallet. It is safe to call while the wallet is in use.
+func (w *HdWallet) SetLimits(l Limits) {
+	w.policy.set(l)
+}
+
+// Stats returns the policy counters of the wallet.
+func (w *HdWallet) Stats() Stats {
+	w.policy.mu.Lock()
+	defer w.policy.mu.Unlock()
+
+	return w.policy.stats
+}
+
+// policy enforces Limits; its zero value allows everything.
+type policy struct {
+	mu          sync.Mutex
+	limits      Limits
+	now         func() time.Time // replaced in tests
+	derivations window
+	signatures  window
+	stats       Stats
+}
+
+// window counts events within a fixed one-minute window.
+type window struct {
+	start time.Time
+	count uint32
+}
+
+func (p *policy) set(l Limits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l.AllowedAccounts = append([]uint32(nil), l.AllowedAccounts...)
+	p.limits = l
+}
+
+// current returns a copy of the limits.
+func (p *policy) current() Limits {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l := p.limits
+	l.AllowedAccounts = append([]uint32(nil), l.AllowedAccounts...)
+
+	return l
+}
+
+// limited reports whether any limit is set.
+func (p *policy) limited() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l := p.limits
+
+	return l.MaxDerivationsPerMinute != 0 || l.MaxSignaturesPerMinute != 0 || l.MaxIndex != 0 ||
+		len(l.AllowedAccounts) != 0
+}
+
+// allowDerivation checks the limits for deriving addrNum of wallet and, if allowed, counts the derivation.
+func (p *policy) allowDerivation(wallet, addrNum uint32) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.check(wallet, addrNum); err != nil {
+		p.stats.Rejected++
+
+		return err
+	}
+
+	p.derivations.count++
+	p.stats.Derivations++
+
+	return nil
+}
+
+// allowSignature checks the signature rate limit and, if allowed, counts the signature.
+func (p *policy) allowSignature() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.rate(&p.signatures, p.limits.MaxSignaturesPerMinute, "MaxSignaturesPerMinute"); err != nil {
+		p.stats.Rejected++
+
+		return err
+	}
+
+	p.signatures.count++
+	p.stats.Signatures++
+
+	return nil
+}
+
+func (p *policy) check(wallet, addrNum uint32) error {
+	if p.limits.MaxIndex != 0 && addrNum > p.limits.MaxIndex {
+		return fmt.Errorf("%w: index %d above MaxIndex %d", ErrPolicyExceeded, addrNum, p.limits.MaxIndex)
+	}
+
+	if len(p.limits.AllowedAccounts) != 0 && !containsUint32(p.limits.AllowedAccounts, wallet) {
+		return fmt.Errorf("%w: wallet %d not in AllowedAccounts", ErrPolicyExceeded, wallet)
+	}
+
+	return p.rate(&p.derivations, p.limits.MaxDerivationsPerMinute, "MaxDerivationsPerMinute")
+}
+
+// rate checks the count of win against limit, named name in the error, starting a new window once a minute passed.
+func (p *policy) rate(win *window, limit uint32, name string) error {
+	if limit == 0 {
+		return nil
+	}
+
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+
+	if t := now(); t.Sub(win.start) >= time.Minute {
+		win.start, win.count = t, 0
+	}
+
+	if win.count >= limit {
+		return fmt.Errorf("%w: %s %d reached", ErrPolicyExceeded, name, limit)
+	}
+
+	return nil
+}
+
+func containsUint32(s []uint32, v uint32) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package hd
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimits(t *testing.T) {
+	w := testWallet(t, WithLimits(Limits{MaxIndex: 10, AllowedAccounts: []uint32{1, 2}}))
+
+	tests := []struct {
+		wallet, addrNum uint32
+		ok              bool
+	}{
+		{2, 0, true},
+		{1, 10, true},
+		{2, 11, false}, // above MaxIndex
+		{3, 0, false},  // account not allowed
+	}
+
+	for i, tt := range tests {
+		_, _, _, err := w.Address(tt.wallet, External, tt.addrNum)
+		if tt.ok && err != nil {
+			t.Errorf("%d: unexpected error %v", i, err)
+		}
+
+		if !tt.ok && !errors.Is(err, ErrPolicyExceeded) {
+			t.Errorf("%d: expected ErrPolicyExceeded, got %v", i, err)
+		}
+	}
+
+	if s := w.Stats(); s.Derivations != 2 || s.Rejected != 2 {
+		t.Errorf("Stats do not match. Got:%+v", s)
+	}
+
+	// limits can be lifted at runtime
+	w.SetLimits(Limits{})
+
+	if _, _, _, err := w.Address(3, External, 11); err != nil {
+		t.Errorf("Address after SetLimits: %v", err)
+	}
+}
+
+func TestLimitsRate(t *testing.T) {
+	const perMinute = 50
+
+	now := time.Unix(0, 0)
+	w := testWallet(t, WithLimits(Limits{MaxDerivationsPerMinute: perMinute}))
+	w.policy.now = func() time.Time { return now }
+
+	// concurrent callers cannot derive more than the limit within the window
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		rejected int
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := uint32(0); j < 10; j++ {
+				_, _, _, err := w.Address(uint32(i), External, j)
+				if errors.Is(err, ErrPolicyExceeded) {
+					mu.Lock()
+					rejected++
+					mu.Unlock()
+				} else if err != nil {
+					t.Errorf("Address: %v", err)
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if rejected != 8*10-perMinute {
+		t.Errorf("Expected %d rejections, got %d", 8*10-perMinute, rejected)
+	}
+
+	// a new window starts after a minute
+	now = now.Add(time.Minute)
+
+	if _, _, _, err := w.Address(0, External, 0); err != nil {
+		t.Errorf("Address in new window: %v", err)
+	}
+
+	if s := w.Stats(); s.Derivations != perMinute+1 || s.Rejected != uint64(rejected) {
+		t.Errorf("Stats do not match. Got:%+v", s)
+	}
+}
+
+func TestLimitsSignatures(t *testing.T) {
+	now := time.Unix(0, 0)
+	w := testWallet(t, WithLimits(Limits{MaxSignaturesPerMinute: 2}))
+	w.policy.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := w.SignMessage(0, External, 0, []byte("msg"))
+		if i < 2 && err != nil {
+			t.Errorf("%d: unexpected error %v", i, err)
+		}
+
+		if i == 2 && !errors.Is(err, ErrPolicyExceeded) {
+			t.Errorf("%d: expected ErrPolicyExceeded, got %v", i, err)
+		}
+	}
+
+	// deriving addresses is not limited by signatures
+	if _, _, _, err := w.Address(0, External, 1); err != nil {
+		t.Errorf("Address: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+
+	if _, err := w.SignMessage(0, External, 0, []byte("msg")); err != nil {
+		t.Errorf("SignMessage in new window: %v", err)
+	}
+
+	if s := w.Stats(); s.Signatures != 3 || s.Derivations != 5 || s.Rejected != 1 {
+		t.Errorf("Stats do not match. Got:%+v", s)
+	}
+}
@@ -0,0 +1,106 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ownershipProofVersion is the version of the statement signed by ProveOwnership.
+const ownershipProofVersion = 1
+
+// ErrInvalidProof will be reported for ownership proofs that cannot be verified or whose signer is not the address.
+var ErrInvalidProof error = errors.New("hd: invalid ownership proof")
+
+// OwnershipProof is a signed statement binding an address to a challenge, as returned by ProveOwnership.
+type OwnershipProof struct {
+	Version   int    `json:"version"`
+	Address   string `json:"address"`   // EIP-55 checksummed
+	Path      string `json:"path"`      // derivation path of the address
+	Challenge []byte `json:"challenge"` // base64 in JSON
+	Timestamp int64  `json:"timestamp"` // Unix time in seconds
+	Signature []byte `json:"signature"` // EIP-191 signature of the statement, R || S || V
+}
+
+// ownershipStatement is the statement signed by an OwnershipProof. Fields are in alphabetical order, so its JSON
+// encoding is the sorted-field serialization of the proof.
+type ownershipStatement struct {
+	Address   string `json:"address"`
+	Challenge string `json:"challenge"` // 0x-prefixed hex
+	Path      string `json:"path"`
+	Timestamp int64  `json:"timestamp"`
+	Version   int    `json:"version"`
+}
+
+// ProveOwnership signs a statement binding the address generated by Address for 'wallet', flg and address number to
+// challenge, with EIP-191 personal_sign over the JSON encoding of the address, hex challenge, path, timestamp and
+// version, keys sorted and without spaces. It is only available for Ethereum and Ethereum Classic wallets.
+func (w *HdWallet) ProveOwnership(wallet uint32, flg uint8, addrNum uint32, challenge []byte) (*OwnershipProof, error) {
+	if w.coin != CoinETH && w.coin != CoinETC {
+		return nil, ErrUnsupportedCoin
+	}
+
+	addr, key, _, err := w.Address(wallet, flg, addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	p := &OwnershipProof{
+		Version:   ownershipProofVersion,
+		Address:   ChecksumAddress(addr),
+		Path:      w.addressPath(wallet, flg, hardened+addrNum).String(),
+		Challenge: append([]byte(nil), challenge...),
+		Timestamp: time.Now().Unix(),
+	}
+
+	if p.Signature, err = w.sign(key, personalHash(p.statement())); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// VerifyOwnership checks the signature of p recovers to its address. It does not check the address derives from the
+// path, which needs the wallet.
+func VerifyOwnership(p *OwnershipProof) error {
+	if p == nil || p.Version != ownershipProofVersion {
+		return fmt.Errorf("%w: unsupported version", ErrInvalidProof)
+	}
+
+	if err := ValidateAddress(p.Address); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidProof, err.Error())
+	}
+
+	if _, err := ParsePath(p.Path); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidProof, err.Error())
+	}
+
+	signer, err := RecoverAddress(p.statement(), p.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidProof, err.Error())
+	}
+
+	addr, _ := hex.DecodeString(p.Address[2:])
+	if !bytes.Equal(signer, addr) {
+		return fmt.Errorf("%w: signed by %s", ErrInvalidProof, ChecksumAddress(signer))
+	}
+
+	return nil
+}
+
+// statement returns the serialization of p that is signed.
+func (p *OwnershipProof) statement() []byte {
+	b, _ := json.Marshal(ownershipStatement{
+		Address:   p.Address,
+		Challenge: "0x" + hex.EncodeToString(p.Challenge),
+		Path:      p.Path,
+		Timestamp: p.Timestamp,
+		Version:   p.Version,
+	})
+
+	return b
+}
@@ -0,0 +1,45 @@
+package hd
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// PublicKey returns the 33-byte compressed and 65-byte uncompressed SEC1 encodings of the public key of the address
+// generated by Address for 'wallet', flg and address number. As that address number is a hardened index, it needs
+// the private key: watch-only wallets return ErrWatchOnly, see PublicKeyBIP44.
+func (w *HdWallet) PublicKey(wallet uint32, flg uint8, addrNum uint32) (compressed, uncompressed []byte, err error) {
+	if w.WatchOnly() {
+		return nil, nil, ErrWatchOnly
+	}
+
+	return w.publicKey(wallet, flg, addrNum, hdkeychain.HardenedKeyStart+addrNum)
+}
+
+// PublicKeyBIP44 returns the public key of the address generated by AddressBIP44 and PublicAddress, like PublicKey
+// does. It also works on watch-only wallets. addrNum must be below 2^31.
+func (w *HdWallet) PublicKeyBIP44(wallet uint32, flg uint8, addrNum uint32,
+) (compressed, uncompressed []byte, err error) {
+	if addrNum >= hardened {
+		return nil, nil, ErrIndexOutOfRange
+	}
+
+	return w.publicKey(wallet, flg, addrNum, addrNum)
+}
+
+func (w *HdWallet) publicKey(wallet uint32, flg uint8, addrNum, child uint32,
+) (compressed, uncompressed []byte, err error) {
+	k, err := w.derive(wallet, flg, addrNum, child)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer k.Zero()
+
+	pub, err := k.ECPubKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return pub.SerializeCompressed(), pub.SerializeUncompressed(), nil
+}
@@ -0,0 +1,55 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+func TestPublicKey(t *testing.T) {
+	w := testWallet(t)
+
+	wo, err := w.Neuter(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := uint32(0); i < 3; i++ {
+		comp, uncomp, err := w.PublicKey(2, External, i)
+		if err != nil || len(comp) != 33 || len(uncomp) != 65 {
+			t.Fatalf("PublicKey. Got:%x %x, err:%v", comp, uncomp, err)
+		}
+
+		addr, key, _, _ := w.Address(2, External, i)
+		if got := keccak256(uncomp[1:])[12:]; !bytes.Equal(got, addr) {
+			t.Errorf("Address does not match. Got:%x, expected:%x", got, addr)
+		}
+
+		prv, _ := btcec.PrivKeyFromBytes(key)
+		if exp := prv.PubKey().SerializeCompressed(); !bytes.Equal(comp, exp) {
+			t.Errorf("Compressed key does not match. Got:%x, expected:%x", comp, exp)
+		}
+
+		// BIP44 keys, also from the watch-only wallet
+		comp, uncomp, _ = w.PublicKeyBIP44(2, External, i)
+		wcomp, wuncomp, err := wo.PublicKeyBIP44(2, External, i)
+
+		if err != nil || !bytes.Equal(comp, wcomp) || !bytes.Equal(uncomp, wuncomp) {
+			t.Errorf("Watch-only key does not match. Got:%x, expected:%x, err:%v", wcomp, comp, err)
+		}
+
+		if addr, _ = wo.PublicAddress(2, External, i); !bytes.Equal(keccak256(uncomp[1:])[12:], addr) {
+			t.Errorf("BIP44 address does not match. Got:%x, expected:%x", keccak256(uncomp[1:])[12:], addr)
+		}
+	}
+
+	if _, _, err = wo.PublicKey(2, External, 0); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+
+	if _, _, err = w.PublicKeyBIP44(2, External, hardened); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package hd
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const registryRetries = 100 // address numbers NextAddress tries when other callers take them first
+
+// ErrIndexInUse will be reported by a Registry recording an address number, or address, that is already recorded.
+var ErrIndexInUse error = errors.New("hd: address index already in use")
+
+// AddressEntry is an address handed out by NextAddress.
+type AddressEntry struct {
+	Wallet  uint32
+	Flag    uint8
+	Index   uint32 // address number
+	Address []byte
+	Meta    map[string]string // e.g. the customer the address was given to
+}
+
+// Registry stores the addresses handed out by NextAddress, e.g. in a database. Implementations must be safe for
+// concurrent use.
+type Registry interface {
+	// NextIndex returns the lowest address number of 'wallet' and flg that is not recorded yet.
+	NextIndex(wallet uint32, flg uint8) (uint32, error)
+	// Record stores entry, or reports ErrIndexInUse if its address number, or address, is already recorded, as a
+	// unique index of a database table would. This is what keeps concurrent callers from sharing an address.
+	Record(entry AddressEntry) error
+	// Lookup returns the entry recorded for addr, if any.
+	Lookup(addr []byte) (AddressEntry, bool, error)
+}
+
+// NextAddress derives the address of the next unused address number of 'wallet' and flg in reg, as Address would,
+// and records it with meta. Concurrent callers sharing reg never get the same address number: when Record reports
+// ErrIndexInUse the following number is tried. Nothing is recorded on a failed derivation, so it consumes no index,
+// except for the rare numbers whose key cannot be derived at all (a DerivationError on the address number): these
+// are skipped.
+func (w *HdWallet) NextAddress(reg Registry, wallet uint32, flg uint8, meta map[string]string) (AddressEntry, error) {
+	return nextAddress(reg, wallet, flg, meta, w.Address)
+}
+
+// addressFunc derives an address as HdWallet.Address does.
+type addressFunc func(wallet uint32, flg uint8, addrNum uint32) (addr, key []byte, prv ecdsa.PrivateKey, err error)
+
+// nextAddress is NextAddress deriving the addresses with derive.
+func nextAddress(reg Registry, wallet uint32, flg uint8, meta map[string]string, derive addressFunc,
+) (AddressEntry, error) {
+	index, err := reg.NextIndex(wallet, flg)
+	if err != nil {
+		return AddressEntry{}, err
+	}
+
+	for i := 0; i < registryRetries; i++ {
+		addr, key, _, err := derive(wallet, flg, index)
+		zero(key)
+
+		// skip an address number whose key cannot be derived, but not a failure of the account or change keys
+		var de *DerivationError
+		if errors.As(err, &de) && de.Component == len(de.Path)-1 {
+			index++
+
+			continue
+		}
+
+		if err != nil {
+			return AddressEntry{}, fmt.Errorf("hd: address %d: %w", index, err)
+		}
+
+		entry := AddressEntry{Wallet: wallet, Flag: flg, Index: index, Address: addr, Meta: copyMeta(meta)}
+
+		err = reg.Record(entry)
+		if err == nil {
+			return entry, nil
+		}
+
+		if !errors.Is(err, ErrIndexInUse) {
+			return AddressEntry{}, err
+		}
+
+		// another caller took index; go on from the lowest free number, past the ones tried already
+		next, err := reg.NextIndex(wallet, flg)
+		if err != nil {
+			return AddressEntry{}, err
+		}
+
+		if index++; next > index {
+			index = next
+		}
+	}
+
+	return AddressEntry{}, fmt.Errorf("%w: gave up after %d attempts", ErrIndexInUse, registryRetries)
+}
+
+// MemoryRegistry is a Registry keeping the entries in memory, for tests or as a reference for other implementations.
+type MemoryRegistry struct {
+	mu      sync.Mutex
+	next    map[branchKey]uint32 // lowest address number that may be free
+	used    map[registryKey]bool
+	entries map[string]AddressEntry // by hex address
+}
+
+type registryKey struct {
+	wallet uint32
+	flg    uint8
+	index  uint32
+}
+
+// NewMemoryRegistry returns an empty MemoryRegistry.
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		next:    make(map[branchKey]uint32),
+		used:    make(map[registryKey]bool),
+		entries: make(map[string]AddressEntry),
+	}
+}
+
+// NextIndex returns the lowest address number of 'wallet' and flg not recorded yet, see Registry.
+func (r *MemoryRegistry) NextIndex(wallet uint32, flg uint8) (uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := branchKey{wallet: wallet, flg: flg}
+	k := registryKey{wallet: wallet, flg: flg, index: r.next[b]}
+
+	for r.used[k] {
+		k.index++
+	}
+
+	r.next[b] = k.index
+
+	return k.index, nil
+}
+
+// Record stores entry, see Registry.
+func (r *MemoryRegistry) Record(entry AddressEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, addr := registryKey{wallet: entry.Wallet, flg: entry.Flag, index: entry.Index}, hex.EncodeToString(entry.Address)
+
+	if _, ok := r.entries[addr]; ok || r.used[k] {
+		return fmt.Errorf("%w: wallet %d, flag %d, index %d", ErrIndexInUse, entry.Wallet, entry.Flag, entry.Index)
+	}
+
+	entry.Address, entry.Meta = append([]byte(nil), entry.Address...), copyMeta(entry.Meta)
+	r.used[k], r.entries[addr] = true, entry
+
+	return nil
+}
+
+// Lookup returns the entry recorded for addr, see Registry.
+func (r *MemoryRegistry) Lookup(addr []byte) (AddressEntry, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[hex.EncodeToString(addr)]
+	if ok {
+		entry.Address, entry.Meta = append([]byte(nil), entry.Address...), copyMeta(entry.Meta)
+	}
+
+	return entry, ok, nil
+}
+
+// copyMeta returns a copy of meta, so that entries do not share it with callers.
+func copyMeta(meta map[string]string) map[string]string {
+	if meta == nil {
+		return nil
+	}
+
+	c := make(map[string]string, len(meta))
+	for k, v := range meta {
+		c[k] = v
+	}
+
+	return c
+}
@@ -0,0 +1,166 @@
+package hd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+func TestNextAddress(t *testing.T) {
+	w := testWallet(t)
+	reg := NewMemoryRegistry()
+
+	for i := uint32(0); i < 3; i++ {
+		entry, err := w.NextAddress(reg, 2, Change, map[string]string{"user": strconv.Itoa(int(i))})
+		if err != nil || entry.Index != i {
+			t.Fatalf("Got:%d, expected:%d, err:%v", entry.Index, i, err)
+		}
+
+		if exp, _, _, _ := w.Address(2, Change, i); !bytes.Equal(entry.Address, exp) {
+			t.Errorf("Address does not match. Got:%x, expected:%x", entry.Address, exp)
+		}
+
+		found, ok, err := reg.Lookup(entry.Address)
+		if err != nil || !ok || found.Index != i || found.Flag != Change || found.Meta["user"] != strconv.Itoa(int(i)) {
+			t.Errorf("Lookup. Got:%+v %t, err:%v", found, ok, err)
+		}
+	}
+
+	// other branches are counted on their own
+	if entry, _ := w.NextAddress(reg, 2, External, nil); entry.Index != 0 {
+		t.Errorf("Got:%d, expected:0", entry.Index)
+	}
+
+	if _, ok, _ := reg.Lookup([]byte{1, 2, 3}); ok {
+		t.Errorf("Unexpected entry for an unknown address")
+	}
+
+	// recording a taken index fails
+	addr, _, _, _ := w.Address(2, Change, 1)
+	if err := reg.Record(AddressEntry{Wallet: 2, Flag: Change, Index: 1, Address: addr}); !errors.Is(err, ErrIndexInUse) {
+		t.Errorf("Expected ErrIndexInUse, got %v", err)
+	}
+}
+
+func TestNextAddressFailure(t *testing.T) {
+	reg := NewMemoryRegistry()
+	w := testWallet(t, WithLimits(Limits{MaxIndex: 1}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := w.NextAddress(reg, 0, External, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the failed derivation does not consume the index
+	if _, err := w.NextAddress(reg, 0, External, nil); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	if entry, err := testWallet(t).NextAddress(reg, 0, External, nil); err != nil || entry.Index != 2 {
+		t.Errorf("Got:%d, expected:2, err:%v", entry.Index, err)
+	}
+}
+
+func TestNextAddressConcurrent(t *testing.T) {
+	w := testWallet(t)
+	reg := NewMemoryRegistry()
+
+	const goroutines, perGoroutine = 32, 4
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[uint32]bool)
+	)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < perGoroutine; i++ {
+				entry, err := w.NextAddress(reg, 1, External, nil)
+				if err != nil {
+					t.Errorf("NextAddress %v", err)
+
+					return
+				}
+
+				mu.Lock()
+				if seen[entry.Index] {
+					t.Errorf("Index %d handed out twice", entry.Index)
+				}
+				seen[entry.Index] = true
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// indices are allocated without gaps
+	for i := uint32(0); i < goroutines*perGoroutine; i++ {
+		if !seen[i] {
+			t.Errorf("Index %d not handed out", i)
+		}
+	}
+}
+
+func TestNextAddressSkip(t *testing.T) {
+	w := testWallet(t)
+	reg := NewMemoryRegistry()
+
+	// index 1 cannot be derived, as if its key were invalid
+	derive := func(wallet uint32, flg uint8, index uint32) ([]byte, []byte, ecdsa.PrivateKey, error) {
+		if index == 1 {
+			p := w.addressPath(wallet, flg, hardened+index)
+
+			return nil, nil, ecdsa.PrivateKey{}, &DerivationError{Path: p, Component: 4, Err: hdkeychain.ErrInvalidChild}
+		}
+
+		return w.Address(wallet, flg, index)
+	}
+
+	for _, exp := range []uint32{0, 2, 3} {
+		if entry, err := nextAddress(reg, 0, External, nil, derive); err != nil || entry.Index != exp {
+			t.Errorf("Got:%d, expected:%d, err:%v", entry.Index, exp, err)
+		}
+	}
+}
+
+func TestMemoryRegistryNextIndex(t *testing.T) {
+	reg := NewMemoryRegistry()
+
+	// the lowest unrecorded number, recording out of order leaves a gap
+	for _, index := range []uint32{0, 1, 3} {
+		if err := reg.Record(AddressEntry{Wallet: 1, Flag: Change, Index: index, Address: []byte{byte(index)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, exp := range []uint32{2, 2} {
+		if index, err := reg.NextIndex(1, Change); err != nil || index != exp {
+			t.Errorf("Got:%d, expected:%d, err:%v", index, exp, err)
+		}
+	}
+
+	if err := reg.Record(AddressEntry{Wallet: 1, Flag: Change, Index: 2, Address: []byte{2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if index, err := reg.NextIndex(1, Change); err != nil || index != 4 {
+		t.Errorf("Got:%d, expected:4, err:%v", index, err)
+	}
+
+	// the same address cannot be recorded twice
+	if err := reg.Record(AddressEntry{Wallet: 1, Flag: Change, Index: 5, Address: []byte{2}}); !errors.Is(err, ErrIndexInUse) {
+		t.Errorf("Expected ErrIndexInUse, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package hd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const branchDepth = 2 // m/44'/coin'
+
+// ErrInvalidXprv will be reported for extended keys that are not the serialized branch of a wallet.
+var ErrInvalidXprv error = errors.New("hd: invalid wallet extended private key")
+
+// Serialize returns the base58 extended private key of the m/44'/coin' branch of the wallet, from which Restore
+// rebuilds it without the seed. It must be kept as safe as the seed. The key reaches every account, so it is not
+// returned while the wallet has limits, see WithLimits.
+func (w *HdWallet) Serialize() (string, error) {
+	if w.WatchOnly() {
+		return "", ErrWatchOnly
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return "", ErrClosed
+	}
+
+	if w.policy.limited() {
+		return "", fmt.Errorf("%w: branch key of a wallet with limits", ErrPolicyExceeded)
+	}
+
+	return w.key.String(), nil
+}
+
+// Restore rebuilds a wallet from the extended private key returned by Serialize, with the given options. The coin
+// type is taken from the key's child index. Public keys and keys that are not at depth 2 under a hardened index are
+// rejected with ErrInvalidXprv. The network is taken from the version bytes, xprv or tprv, unless WithNetwork is
+// given, in which case keys of other networks are rejected with ErrNetworkMismatch.
+func Restore(xprv string, opts ...Option) (*HdWallet, error) {
+	k, err := hdkeychain.NewKeyFromString(xprv)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXprv, err.Error())
+	}
+
+	if !k.IsPrivate() {
+		return nil, fmt.Errorf("%w: key is public", ErrInvalidXprv)
+	}
+
+	w := &HdWallet{}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err = w.checkNetwork(k.Version()); err != nil {
+		k.Zero()
+
+		return nil, err
+	}
+
+	if k.Depth() != branchDepth || k.ChildIndex() < hdkeychain.HardenedKeyStart {
+		k.Zero()
+
+		return nil, fmt.Errorf("%w: depth %d, child %d", ErrInvalidXprv, k.Depth(), k.ChildIndex())
+	}
+
+	// memoize the public key, so that concurrent derivations only read the key
+	if _, err = k.ECPubKey(); err != nil {
+		k.Zero()
+
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXprv, err.Error())
+	}
+
+	w.key, w.coin = k, k.ChildIndex()-hdkeychain.HardenedKeyStart
+
+	return w, nil
+}
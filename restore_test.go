@@ -0,0 +1,104 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestRestore(t *testing.T) {
+	w := testWallet(t)
+
+	xprv, err := w.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Restore(xprv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Coin() != CoinETH {
+		t.Errorf("Coin does not match. Got:%d, expected:%d", r.Coin(), CoinETH)
+	}
+
+	for _, tt := range []struct {
+		wallet, addrNum uint32
+		flg             uint8
+	}{{0, 0, External}, {2, 1, External}, {2, 7, Change}, {9, 1000, Change}} {
+		addr, key, _, _ := w.Address(tt.wallet, tt.flg, tt.addrNum)
+		if raddr, rkey, _, err := r.Address(tt.wallet, tt.flg, tt.addrNum); err != nil || !bytes.Equal(addr, raddr) ||
+			!bytes.Equal(key, rkey) {
+			t.Errorf("Address does not match. Got:%x %x, expected:%x %x, err:%v", raddr, rkey, addr, key, err)
+		}
+
+		addr, key, _, _ = w.AddressBIP44(tt.wallet, tt.flg, tt.addrNum)
+		if raddr, rkey, _, err := r.AddressBIP44(tt.wallet, tt.flg, tt.addrNum); err != nil ||
+			!bytes.Equal(addr, raddr) || !bytes.Equal(key, rkey) {
+			t.Errorf("AddressBIP44 does not match. Got:%x %x, expected:%x %x, err:%v", raddr, rkey, addr, key, err)
+		}
+	}
+
+	// the coin type travels with the key
+	seed, _ := SeedFromMnemonic(testMnemonic, "password")
+	btc, _ := InitForCoin(seed, CoinBTC)
+	xprv, _ = btc.Serialize()
+
+	if r, err = Restore(xprv); err != nil || r.Coin() != CoinBTC {
+		t.Errorf("Restore BTC. Got:%v, err:%v", r, err)
+	}
+
+	// closing the restored wallet does not affect the original
+	_ = r.Close()
+
+	if _, err = btc.Serialize(); err != nil {
+		t.Errorf("Serialize after closing the restored wallet: %v", err)
+	}
+
+	// the branch key would lift the limits of the wallet
+	btc.SetLimits(Limits{MaxIndex: 10})
+
+	if _, err = btc.Serialize(); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	_ = btc.Close()
+
+	if _, err = btc.Serialize(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	w := testWallet(t)
+
+	pub, _ := w.key.Neuter()
+	acct, _ := w.key.Derive(hdkeychain.HardenedKeyStart)
+	master, _ := hdkeychain.NewMaster(bytes.Repeat([]byte{1}, 32), &chaincfg.MainNetParams)
+	unhardened := hdkeychain.NewExtendedKey([]byte{0x04, 0x88, 0xad, 0xe4}, bytes.Repeat([]byte{1}, 32),
+		bytes.Repeat([]byte{2}, 32), []byte{0, 0, 0, 0}, 2, 60, true)
+	ltc := hdkeychain.NewExtendedKey([]byte{0x01, 0x9d, 0x9c, 0xfe}, bytes.Repeat([]byte{1}, 32),
+		bytes.Repeat([]byte{2}, 32), []byte{0, 0, 0, 0}, 2, hardened+2, true)
+
+	for _, s := range []string{"xprv", pub.String(), acct.String(), master.String(), unhardened.String(),
+		ltc.String()} {
+		if _, err := Restore(s); !errors.Is(err, ErrInvalidXprv) {
+			t.Errorf("%s: expected ErrInvalidXprv, got %v", s, err)
+		}
+	}
+
+	wo, _ := w.Neuter(0)
+	if _, err := wo.Serialize(); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+
+	_ = w.Close()
+
+	if _, err := w.Serialize(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
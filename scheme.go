@@ -0,0 +1,80 @@
+package hd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Scheme is a derivation layout used by wallet software, mapping an index to a path.
+type Scheme int
+
+// Derivation schemes, coin being the SLIP-44 coin type of the wallet, 60 for Ethereum.
+const (
+	SchemeCurrent      Scheme = iota // m/44'/coin'/0'/0/i', as Address(0, External, i)
+	SchemeMetaMask                   // m/44'/coin'/0'/0/i, also used by Trezor and MyEtherWallet
+	SchemeLedgerLive                 // m/44'/coin'/i'/0/0, one account per index
+	SchemeLedgerLegacy               // m/44'/coin'/0'/i, the Ledger Chrome app and MyEtherWallet "Ledger" path
+)
+
+// ErrInvalidScheme will be reported for an unknown Scheme.
+var ErrInvalidScheme error = errors.New("hd: invalid derivation scheme")
+
+// String returns the name of the scheme.
+func (s Scheme) String() string {
+	switch s {
+	case SchemeCurrent:
+		return "Current"
+	case SchemeMetaMask:
+		return "MetaMask"
+	case SchemeLedgerLive:
+		return "LedgerLive"
+	case SchemeLedgerLegacy:
+		return "LedgerLegacy"
+	default:
+		return fmt.Sprintf("Scheme(%d)", int(s))
+	}
+}
+
+// Path returns the path the scheme maps index to for coin. The index must be below 2^31.
+func (s Scheme) Path(coin, index uint32) (Path, error) {
+	if index >= hardened {
+		return nil, ErrIndexOutOfRange
+	}
+
+	base := Path{hardened + purpose, hardened + coin}
+
+	switch s {
+	case SchemeCurrent:
+		return append(base, hardened, uint32(External), hardened+index), nil
+	case SchemeMetaMask:
+		return append(base, hardened, uint32(External), index), nil
+	case SchemeLedgerLive:
+		return append(base, hardened+index, uint32(External), 0), nil
+	case SchemeLedgerLegacy:
+		return append(base, hardened, index), nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrInvalidScheme, int(s))
+	}
+}
+
+// AddressWithScheme returns the address and private key bytes the scheme maps index to, see Scheme for the paths.
+func (w *HdWallet) AddressWithScheme(scheme Scheme, index uint32) (addr, key []byte, err error) {
+	if w.WatchOnly() {
+		return nil, nil, ErrWatchOnly
+	}
+
+	p, err := scheme.Path(w.coin, index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k, err := w.derivePath(p, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer k.Zero()
+
+	addr, key, _ = w.keys(k)
+
+	return addr, key, nil
+}
@@ -0,0 +1,102 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestAddressWithScheme(t *testing.T) {
+	const junk = "test test test test test test test test test test test junk"
+
+	const about = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	tests := []struct {
+		mnemonic string
+		scheme   Scheme
+		index    uint32
+		addr     string
+		key      string
+	}{
+		{junk, SchemeCurrent, 1, "b476a31fd5870257716a0700d0ac682d7b8c3752",
+			"37496b4a1b1e717b5be02d672598620727ce28e1fe158eeccfd0f29607f785a0"},
+		{junk, SchemeMetaMask, 1, "70997970c51812dc3a010c7d01b50e0d17dc79c8",
+			"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"},
+		{junk, SchemeLedgerLive, 0, "f39fd6e51aad88f6f4ce6ab8827279cfffb92266",
+			"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"},
+		{junk, SchemeLedgerLive, 1, "8c8d35429f74ec245f8ef2f4fd1e551cff97d650",
+			"7797c0f3db8b946604ec2039dfd9763e4ffdc53174342a2ed9b14fa3eda666a5"},
+		{junk, SchemeLedgerLegacy, 1, "c89d42189f0450c2b2c3c61f58ec5d628176a1e7",
+			"f1aa5a7966c3863ccde3047f6a1e266cdc0c76b399e256b8fede92b1c69e4f4e"},
+		{about, SchemeCurrent, 0, "0231d6dfb3e3efb93971a99a2c034a54d03c055b",
+			"1aae7fe31685db51be296831646fa97add77c00ceb148e9ecf82f0e2b10a94e2"},
+		{about, SchemeMetaMask, 1, "6fac4d18c912343bf86fa7049364dd4e424ab9c0",
+			"9a983cb3d832fbde5ab49d692b7a8bf5b5d232479c99333d0fc8e1d21f1b55b6"},
+		{about, SchemeLedgerLive, 1, "78839f6054d7ed13918bae0473ba31b1ca9d7265",
+			"318470c858f622e48a80120a1fc3c8460d67a7bf31b3273a6d27d4c013f2f8d3"},
+		{about, SchemeLedgerLegacy, 0, "b8fd42000d00202dcbcf5e18d6640d656345fd6a",
+			"a29ac2cb17e31cdab42a8fe2d83f04f4b69c5e73bc8d3bf6b5dc96ac239b145a"},
+	}
+
+	for i, tt := range tests {
+		seed, _ := SeedFromMnemonic(tt.mnemonic, "")
+
+		w, err := Init(seed)
+		if err != nil {
+			t.Fatalf("Init %e", err)
+		}
+
+		addr, key, err := w.AddressWithScheme(tt.scheme, tt.index)
+		if err != nil {
+			t.Fatalf("%d: AddressWithScheme %e", i, err)
+		}
+
+		if exp, _ := hex.DecodeString(tt.addr); !bytes.Equal(addr, exp) {
+			t.Errorf("%d %s: address does not match. Got:%x, expected:%s", i, tt.scheme, addr, tt.addr)
+		}
+
+		if exp, _ := hex.DecodeString(tt.key); !bytes.Equal(key, exp) {
+			t.Errorf("%d %s: key does not match. Got:%x, expected:%s", i, tt.scheme, key, tt.key)
+		}
+	}
+}
+
+func TestSchemePath(t *testing.T) {
+	tests := []struct {
+		scheme Scheme
+		path   string
+	}{
+		{SchemeCurrent, "m/44'/60'/0'/0/7'"},
+		{SchemeMetaMask, "m/44'/60'/0'/0/7"},
+		{SchemeLedgerLive, "m/44'/60'/7'/0/0"},
+		{SchemeLedgerLegacy, "m/44'/60'/0'/7"},
+	}
+
+	for _, tt := range tests {
+		if p, err := tt.scheme.Path(CoinETH, 7); err != nil || p.String() != tt.path {
+			t.Errorf("%s: Got:%s, expected:%s, err:%v", tt.scheme, p, tt.path, err)
+		}
+	}
+
+	w := testWallet(t)
+
+	// the current scheme is Address of the first wallet, MetaMask the BIP44 one
+	exp, _, _, _ := w.Address(0, External, 3)
+	if addr, _, _ := w.AddressWithScheme(SchemeCurrent, 3); !bytes.Equal(addr, exp) {
+		t.Errorf("Current does not match Address. Got:%x, expected:%x", addr, exp)
+	}
+
+	exp, _, _, _ = w.AddressBIP44(0, External, 3)
+	if addr, _, _ := w.AddressWithScheme(SchemeMetaMask, 3); !bytes.Equal(addr, exp) {
+		t.Errorf("MetaMask does not match AddressBIP44. Got:%x, expected:%x", addr, exp)
+	}
+
+	if _, _, err := w.AddressWithScheme(Scheme(9), 0); !errors.Is(err, ErrInvalidScheme) {
+		t.Errorf("Expected ErrInvalidScheme, got %v", err)
+	}
+
+	if _, _, err := w.AddressWithScheme(SchemeLedgerLive, hardened); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
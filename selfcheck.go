@@ -0,0 +1,165 @@
+package hd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSelfCheckFailed will be reported when a derivation does not give the expected, or the same, result.
+var ErrSelfCheckFailed error = errors.New("hd: self check failed")
+
+// Vector is a known derivation checked by SelfCheck: the absolute Path if set, or else the Address of Wallet, Flag
+// and Index, must give Address and, if set, Key. Both are hex, optionally 0x-prefixed, and case-insensitive.
+type Vector struct {
+	Path    string
+	Wallet  uint32
+	Flag    uint8
+	Index   uint32
+	Address string
+	Key     string
+}
+
+// SelfCheck derives every vector and checks it gives the expected address and key, so that applications can run
+// their own golden vectors at startup and catch a changed derivation before serving traffic.
+func (w *HdWallet) SelfCheck(vectors []Vector) error {
+	for i, v := range vectors {
+		var (
+			addr, key []byte
+			err       error
+		)
+
+		if v.Path != "" {
+			addr, key, err = w.AddressAtPath(v.Path)
+		} else {
+			addr, key, _, err = w.Address(v.Wallet, v.Flag, v.Index)
+		}
+
+		if err == nil {
+			err = v.check(addr, key)
+		}
+
+		zero(key)
+
+		if err != nil {
+			return fmt.Errorf("hd: vector %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// check compares the derived addr and key with the vector.
+func (v *Vector) check(addr, key []byte) error {
+	exp, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(v.Address), "0x"))
+	if err != nil {
+		return fmt.Errorf("%w: invalid address %q", ErrSelfCheckFailed, v.Address)
+	}
+
+	if !bytes.Equal(addr, exp) {
+		return fmt.Errorf("%w: got address %x, expected %x", ErrSelfCheckFailed, addr, exp)
+	}
+
+	if v.Key == "" {
+		return nil
+	}
+
+	expKey, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(v.Key), "0x"))
+	defer zero(expKey)
+
+	if err != nil || !hmac.Equal(key, expKey) {
+		return fmt.Errorf("%w: key of address %x does not match", ErrSelfCheckFailed, addr)
+	}
+
+	return nil
+}
+
+// VerifyRoundTrip derives samples random wallet, flag and address numbers from two wallets initialized separately
+// from seed, one of them without branch cache, checks both give the same addresses and keys with Address and
+// AddressBIP44, and that no address appears twice in the sample.
+func VerifyRoundTrip(seed []byte, samples int) error {
+	if samples < 1 {
+		return fmt.Errorf("%w: %d samples", ErrSelfCheckFailed, samples)
+	}
+
+	a, err := Init(seed)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	b, err := Init(seed, WithCacheSize(0))
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	rnd := make([]byte, 9*samples)
+	if _, err = rand.Read(rnd); err != nil {
+		return fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	seen := make(map[string]string, 2*samples) // path by hex address
+
+	for i := 0; i < samples; i++ {
+		wallet := binary.BigEndian.Uint32(rnd[9*i:]) &^ hardened
+		flg := rnd[9*i+4] & Change
+		index := binary.BigEndian.Uint32(rnd[9*i+5:]) &^ hardened
+
+		err = roundTrip(a, b, a.addressPath(wallet, flg, hardened+index), seen, func(w *HdWallet) ([]byte, []byte, error) {
+			addr, key, _, err := w.Address(wallet, flg, index)
+
+			return addr, key, err
+		})
+		if err != nil {
+			return err
+		}
+
+		err = roundTrip(a, b, a.addressPath(wallet, flg, index), seen, func(w *HdWallet) ([]byte, []byte, error) {
+			addr, key, _, err := w.AddressBIP44(wallet, flg, index)
+
+			return addr, key, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// roundTrip derives the key at p with derive from a and b, compares the results and records the address in seen.
+func roundTrip(a, b *HdWallet, p Path, seen map[string]string, derive func(*HdWallet) ([]byte, []byte, error)) error {
+	addrA, keyA, err := derive(a)
+	defer zero(keyA)
+
+	if err != nil {
+		return err
+	}
+
+	addrB, keyB, err := derive(b)
+	defer zero(keyB)
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(addrA, addrB) || !hmac.Equal(keyA, keyB) {
+		return fmt.Errorf("%w: %s derived %x and %x", ErrSelfCheckFailed, p, addrA, addrB)
+	}
+
+	// the same path may be drawn twice
+	id := hex.EncodeToString(addrA)
+	if prev, ok := seen[id]; ok && prev != p.String() {
+		return fmt.Errorf("%w: address %x derived at %s and %s", ErrSelfCheckFailed, addrA, prev, p)
+	}
+
+	seen[id] = p.String()
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package hd
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestSelfCheck(t *testing.T) {
+	w := testWallet(t)
+
+	vectors := []Vector{
+		{Wallet: 2, Flag: External, Index: 0, Address: "0xD43E2870777916Ede1f5Cc43F14f8C0741e11f96"},
+		{Path: "m/44'/60'/2'/0/1'", Address: "f4cefc8d1afaa51d5a5e7f57d214b60429ca4378",
+			Key: "0xfa7d6a67439ec17e07c10f10a4a9007e46583b5219cb909c8b474398b7216917"},
+		{Path: "m/44'/60'/2'/0/0", Key: "31d97e9a0cf429a3fd9c7713a386aa5cddac69c59d2e3761a5586977a4119812"},
+	}
+
+	// the BIP44 vector only pins the key, fill in its address
+	addr, _, _, _ := w.AddressBIP44(2, External, 0)
+	vectors[2].Address = hex.EncodeToString(addr)
+
+	if err := w.SelfCheck(vectors); err != nil {
+		t.Errorf("SelfCheck %v", err)
+	}
+
+	failing := []Vector{
+		{Wallet: 2, Flag: External, Index: 1, Address: "0xD43E2870777916Ede1f5Cc43F14f8C0741e11f96"},
+		{Wallet: 2, Flag: External, Index: 0, Address: "0xD43E2870777916Ede1f5Cc43F14f8C0741e11f96", Key: "00"},
+		{Wallet: 2, Flag: External, Index: 0, Address: "0xzz"},
+	}
+
+	for i, v := range failing {
+		if err := w.SelfCheck(append(vectors, v)); !errors.Is(err, ErrSelfCheckFailed) {
+			t.Errorf("%d: expected ErrSelfCheckFailed, got %v", i, err)
+		}
+	}
+
+	if err := w.SelfCheck([]Vector{{Path: "m/44'/61'/0'"}}); !errors.Is(err, ErrPathNotInWallet) {
+		t.Errorf("Expected ErrPathNotInWallet, got %v", err)
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	seed, _ := hex.DecodeString(testSeed)
+
+	if err := VerifyRoundTrip(seed, 20); err != nil {
+		t.Errorf("VerifyRoundTrip %v", err)
+	}
+
+	if err := VerifyRoundTrip(seed, 0); !errors.Is(err, ErrSelfCheckFailed) {
+		t.Errorf("Expected ErrSelfCheckFailed, got %v", err)
+	}
+
+	if err := VerifyRoundTrip(seed[:10], 1); !errors.Is(err, ErrInvalidSeedLen) {
+		t.Errorf("Expected ErrInvalidSeedLen, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package hd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+const (
+	signatureLen = 65 // R || S || V
+	recoveryBase = 27 // V of Ethereum signatures is 27 or 28
+	hashLen      = 32
+)
+
+var (
+	// ErrInvalidSignature will be reported for signatures that are malformed, not canonical or that do not recover.
+	ErrInvalidSignature error = errors.New("hd: invalid signature")
+	// ErrInvalidHashLen will be reported when signing a hash that is not 32 bytes long.
+	ErrInvalidHashLen error = errors.New("hd: hash must be 32 bytes")
+)
+
+// SignMessage signs msg with the key of the address generated by Address for 'wallet', flg and address number, as
+// EIP-191 personal_sign does: the keccak-256 hash of "\x19Ethereum Signed Message:\n" + len(msg) + msg is signed and
+// the 65-byte signature R || S || V is returned, with V 27 or 28. Signatures are deterministic (RFC 6979) and low-S.
+func (w *HdWallet) SignMessage(wallet uint32, flg uint8, addrNum uint32, msg []byte) (sig []byte, err error) {
+	if w.coin != CoinETH && w.coin != CoinETC {
+		return nil, ErrUnsupportedCoin
+	}
+
+	_, key, _, err := w.Address(wallet, flg, addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	return w.sign(key, personalHash(msg))
+}
+
+// SignHash signs the 32-byte hash with the key of the address generated by Address for 'wallet', flg and address
+// number, and returns the 65-byte signature R || S || V, with V 27 or 28, like SignMessage. The hash is signed as is:
+// callers, e.g. transaction signers, are responsible for hashing what they sign.
+func (w *HdWallet) SignHash(wallet uint32, flg uint8, addrNum uint32, hash []byte) (sig []byte, err error) {
+	if w.coin != CoinETH && w.coin != CoinETC {
+		return nil, ErrUnsupportedCoin
+	}
+
+	if len(hash) != hashLen {
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidHashLen, len(hash))
+	}
+
+	_, key, _, err := w.Address(wallet, flg, addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	return w.sign(key, hash)
+}
+
+// RecoverAddress returns the address that signed msg with SignMessage, or any other EIP-191 personal_sign
+// implementation. V may be 27 or 28, or 0 or 1. Signatures with a high S are rejected, as on-chain ecrecover users
+// usually do.
+func RecoverAddress(msg, sig []byte) ([]byte, error) {
+	pub, err := recoverPubKey(personalHash(msg), sig)
+	if err != nil {
+		return nil, err
+	}
+
+	return ethAddress(pub), nil
+}
+
+// sign signs hash with key, after checking the signature rate limit, and returns R || S || V.
+func (w *HdWallet) sign(key, hash []byte) ([]byte, error) {
+	if err := w.policy.allowSignature(); err != nil {
+		return nil, err
+	}
+
+	prv, _ := btcec.PrivKeyFromBytes(key)
+	defer prv.Zero()
+
+	// the compact signature is V || R || S, with V 27 + recovery id for uncompressed keys
+	compact, err := ecdsa.SignCompact(prv, hash, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return append(compact[1:], compact[0]), nil
+}
+
+// recoverPubKey returns the public key that signed hash, sig being R || S || V.
+func recoverPubKey(hash, sig []byte) (*btcec.PublicKey, error) {
+	if len(sig) != signatureLen {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(sig))
+	}
+
+	v := sig[signatureLen-1]
+	if v < recoveryBase {
+		v += recoveryBase
+	}
+
+	if v != recoveryBase && v != recoveryBase+1 {
+		return nil, fmt.Errorf("%w: v %d", ErrInvalidSignature, sig[signatureLen-1])
+	}
+
+	var s btcec.ModNScalar
+	if overflow := s.SetByteSlice(sig[32:64]); overflow || s.IsOverHalfOrder() {
+		return nil, fmt.Errorf("%w: s is not canonical", ErrInvalidSignature)
+	}
+
+	pub, _, err := ecdsa.RecoverCompact(append([]byte{v}, sig[:64]...), hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSignature, err.Error())
+	}
+
+	return pub, nil
+}
+
+// personalHash returns the EIP-191 version 0x45 hash of msg.
+func personalHash(msg []byte) []byte {
+	return keccak256([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(len(msg))), msg)
+}
@@ -0,0 +1,97 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+func TestSignMessage(t *testing.T) {
+	w := testWallet(t)
+	msg := []byte("I own this address")
+
+	for i, exp := range []string{
+		"d43e2870777916ede1f5cc43f14f8c0741e11f96",
+		"f4cefc8d1afaa51d5a5e7f57d214b60429ca4378",
+		"8a1847459c5fcd66f0b29012a21a2d5a314ef1d0",
+	} {
+		sig, err := w.SignMessage(2, External, uint32(i), msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sig[64] != 27 && sig[64] != 28 {
+			t.Errorf("V is not 27 or 28. Got:%d", sig[64])
+		}
+
+		if addr, err := RecoverAddress(msg, sig); err != nil || hex.EncodeToString(addr) != exp {
+			t.Errorf("Address does not match. Got:%x, expected:%s, err:%v", addr, exp, err)
+		}
+
+		if addr, _ := RecoverAddress([]byte("another message"), sig); hex.EncodeToString(addr) == exp {
+			t.Errorf("Signature recovered for another message")
+		}
+	}
+}
+
+func TestSignHash(t *testing.T) {
+	w := testWallet(t)
+	msg := []byte("I own this address")
+
+	exp, _ := w.SignMessage(2, Change, 3, msg)
+	if sig, err := w.SignHash(2, Change, 3, personalHash(msg)); err != nil || !bytes.Equal(sig, exp) {
+		t.Errorf("Signature does not match. Got:%x, expected:%x, err:%v", sig, exp, err)
+	}
+
+	if _, err := w.SignHash(2, Change, 3, msg); !errors.Is(err, ErrInvalidHashLen) {
+		t.Errorf("Expected ErrInvalidHashLen, got %v", err)
+	}
+}
+
+func TestRecoverAddress(t *testing.T) {
+	// personal_sign of "hello world" by 0x14791697260E4c9A71f18484C9f997B308e59325, as documented by ethers.js
+	sig, _ := hex.DecodeString("ddd0a7290af9526056b4e35a077b9a11b513aa0028ec6c9880948544508f3c63" +
+		"265e99e47ad31bb2cab9646c504576b3abc6939a1710afc08cbf3034d73214b81c")
+	exp := "14791697260e4c9a71f18484c9f997b308e59325"
+
+	if addr, err := RecoverAddress([]byte("hello world"), sig); err != nil || hex.EncodeToString(addr) != exp {
+		t.Errorf("Address does not match. Got:%x, expected:%s, err:%v", addr, exp, err)
+	}
+
+	// V as 0 or 1
+	raw := append([]byte(nil), sig...)
+	raw[64] -= 27
+
+	if addr, err := RecoverAddress([]byte("hello world"), raw); err != nil || hex.EncodeToString(addr) != exp {
+		t.Errorf("Address does not match. Got:%x, expected:%s, err:%v", addr, exp, err)
+	}
+
+	// the malleable high-S twin of the signature: (r, n-s, v^1)
+	var s btcec.ModNScalar
+
+	s.SetByteSlice(sig[32:64])
+	s.Negate()
+
+	high := append([]byte(nil), sig...)
+	sb := s.Bytes()
+	copy(high[32:64], sb[:])
+	high[64] ^= 1
+
+	for _, bad := range [][]byte{sig[:64], high, append(append([]byte(nil), sig[:64]...), 29)} {
+		if _, err := RecoverAddress([]byte("hello world"), bad); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature, got %v", err)
+		}
+	}
+}
+
+func TestSignMessageCoin(t *testing.T) {
+	seed, _ := SeedFromMnemonic(testMnemonic, "password")
+
+	btc, _ := InitForCoin(seed, CoinBTC)
+	if _, err := btc.SignMessage(0, External, 0, []byte("msg")); !errors.Is(err, ErrUnsupportedCoin) {
+		t.Errorf("Expected ErrUnsupportedCoin, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package hd
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	maxSubKeyLen = 255 * sha256.Size // HKDF-SHA256 output limit, 8160 bytes
+	subKeySalt   = "hd subkey v1"    // domain separation from other uses of the key
+)
+
+// ErrInvalidSubKeyLen will be reported for sub-key lengths that are not between 1 and 8160 bytes.
+var ErrInvalidSubKeyLen error = errors.New("hd: invalid sub-key length")
+
+// SubKey derives length bytes of key material for a non-ECDSA use named by label, e.g. encrypting metadata, from the
+// key of the address generated by Address for 'wallet', flg and address number. It is HKDF-SHA256 of the private key
+// with the salt "hd subkey v1" and, as info, the 4-byte big-endian length of label followed by label. Different
+// labels give independent outputs, from which the private key cannot be recovered.
+func (w *HdWallet) SubKey(wallet uint32, flg uint8, addrNum uint32, label string, length int) ([]byte, error) {
+	if length < 1 || length > maxSubKeyLen {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSubKeyLen, length)
+	}
+
+	_, key, _, err := w.Address(wallet, flg, addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	info := make([]byte, 4, 4+len(label))
+	binary.BigEndian.PutUint32(info, uint32(len(label)))
+	info = append(info, label...)
+
+	out := make([]byte, length)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, key, []byte(subKeySalt), info), out); err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package hd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestSubKey(t *testing.T) {
+	w := testWallet(t)
+
+	// pinned outputs for the key of Address(2, External, 0), so that refactors cannot change derived secrets
+	tests := []struct {
+		label  string
+		length int
+		out    string
+	}{
+		{"metadata", 32, "4d64bcebf944ddc06bbd29379575bfcc39e599afb5a94d9f5d77219d5d820964"},
+		{"webhook", 64, "177c05d940fea67392df1573e4c2ee81310cecbcac7da750f7bf413b41e5747be5b5f1ba8a6836b6724bf7b5ecc651d0" +
+			"0f2751cede7852bac7174b9e3dad6c6b"},
+		{"", 16, "b0e708063e56e1908a9ddc433c24eb23"},
+	}
+
+	for _, tt := range tests {
+		out, err := w.SubKey(2, External, 0, tt.label, tt.length)
+		if err != nil {
+			t.Fatalf("%q: SubKey %e", tt.label, err)
+		}
+
+		if hex.EncodeToString(out) != tt.out {
+			t.Errorf("%q: Got:%x, expected:%s", tt.label, out, tt.out)
+		}
+	}
+
+	// outputs are independent of the label, and never the private key
+	_, key, _, _ := w.Address(2, External, 0)
+	a, _ := w.SubKey(2, External, 0, "a", 32)
+	b, _ := w.SubKey(2, External, 0, "b", 32)
+
+	if bytes.Equal(a, b) || bytes.Equal(a, key) {
+		t.Errorf("Sub-keys are not independent: %x %x", a, b)
+	}
+
+	if long, err := w.SubKey(2, External, 0, "a", maxSubKeyLen); err != nil || !bytes.Equal(long[:32], a) {
+		t.Errorf("Got:%x, err:%v", long[:32], err)
+	}
+
+	for _, n := range []int{0, -1, maxSubKeyLen + 1} {
+		if _, err := w.SubKey(2, External, 0, "a", n); !errors.Is(err, ErrInvalidSubKeyLen) {
+			t.Errorf("%d: expected ErrInvalidSubKeyLen, got %v", n, err)
+		}
+	}
+
+	wo, _ := w.Neuter(2)
+	if _, err := wo.SubKey(2, External, 0, "a", 32); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly, got %v", err)
+	}
+}
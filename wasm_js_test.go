@@ -0,0 +1,52 @@
+//go:build js && wasm
+
+package hd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+// TestJSWasm checks the wallet derives, signs and stores its keys on js/wasm.
+func TestJSWasm(t *testing.T) {
+	w := testWallet(t)
+
+	addr, _, _, err := w.Address(2, External, 1)
+	if err != nil || len(addr) != 20 {
+		t.Fatalf("Address. Got:%x, err:%v", addr, err)
+	}
+
+	sig, err := w.SignHash(2, External, 1, personalHash([]byte("wasm")))
+	if err != nil {
+		t.Fatalf("SignHash %e", err)
+	}
+
+	if signer, err := RecoverAddress([]byte("wasm"), sig); err != nil || !bytes.Equal(signer, addr) {
+		t.Errorf("Signer does not match. Got:%x, expected:%x, err:%v", signer, addr, err)
+	}
+
+	// the file system of Node.js
+	path := filepath.Join(t.TempDir(), "wallet")
+	if err = w.SaveEncrypted(path, "secret", WithScryptParams(LightScryptN, LightScryptP)); err != nil {
+		t.Fatalf("SaveEncrypted %e", err)
+	}
+
+	loaded, err := LoadEncrypted(path, "secret")
+	if err != nil {
+		t.Fatalf("LoadEncrypted %e", err)
+	}
+
+	if exp, _, _, _ := loaded.Address(2, External, 1); !bytes.Equal(addr, exp) {
+		t.Errorf("Loaded wallet address does not match. Got:%x, expected:%x", exp, addr)
+	}
+
+	data, err := w.ExportKeystore(2, External, 1, "secret", WithScryptParams(LightScryptN, LightScryptP))
+	if err != nil {
+		t.Fatalf("ExportKeystore %e", err)
+	}
+
+	if _, err = ImportKeystore(data, "secret"); err != nil {
+		t.Errorf("ImportKeystore %e", err)
+	}
+}
@@ -0,0 +1,178 @@
+package hd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+const accountDepth = 3 // m/44'/coin'/wallet'
+
+var (
+	// ErrWatchOnly will be reported when asking a watch-only wallet for private keys.
+	ErrWatchOnly error = errors.New("hd: watch-only wallet has no private keys")
+	// ErrAccountNotAvailable will be reported when a watch-only wallet does not hold the requested account.
+	ErrAccountNotAvailable error = errors.New("hd: account not available in watch-only wallet")
+	// ErrInvalidXpub will be reported for extended keys that are not account-level extended public keys.
+	ErrInvalidXpub error = errors.New("hd: invalid account extended public key")
+)
+
+// Neuter returns a watch-only copy of the wallet holding only the account-level extended public keys of the
+// wallets first and more, so at least one. It can derive addresses with PublicAddress but any request for private
+// keys fails with ErrWatchOnly. The wallets are checked against the limits of w, which also apply to the copy.
+func (w *HdWallet) Neuter(first uint32, more ...uint32) (*HdWallet, error) {
+	wallets := append([]uint32{first}, more...)
+	accounts := make(map[uint32]*hdkeychain.ExtendedKey, len(wallets))
+
+	for _, wallet := range wallets {
+		acct, err := w.publicAccount(wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[wallet] = acct
+	}
+
+	nw := &HdWallet{coin: w.coin, accounts: accounts, net: w.net, lenient: w.lenient}
+	nw.policy.set(w.policy.current())
+
+	return nw, nil
+}
+
+// ExportXpub returns the serialized extended public key of the account m/44'/coin'/wallet', which can be loaded in a
+// watch-only wallet with FromXpub. The wallet is checked against the limits of w.
+func (w *HdWallet) ExportXpub(wallet uint32) (string, error) {
+	acct, err := w.publicAccount(wallet)
+	if err != nil {
+		return "", err
+	}
+
+	return acct.String(), nil
+}
+
+// FromXpub loads a watch-only Ethereum wallet from an account extended public key exported by ExportXpub.
+func FromXpub(xpub string) (*HdWallet, error) {
+	return FromXpubForCoin(xpub, CoinETH)
+}
+
+// FromXpubForCoin loads a watch-only wallet for coinType from an account extended public key exported by
+// ExportXpub. The wallet number is taken from the key's child index and the network from its version bytes, xpub or
+// tpub.
+func FromXpubForCoin(xpub string, coinType uint32) (*HdWallet, error) {
+	acct, err := parseAccountXpub(xpub)
+	if err != nil {
+		return nil, err
+	}
+
+	net := publicNetwork(acct.Version())
+	if net == nil {
+		return nil, fmt.Errorf("%w: version %x", ErrInvalidXpub, acct.Version())
+	}
+
+	wallet := acct.ChildIndex() - hdkeychain.HardenedKeyStart
+
+	return &HdWallet{coin: coinType, accounts: map[uint32]*hdkeychain.ExtendedKey{wallet: acct}, net: net}, nil
+}
+
+// parseAccountXpub parses an account-level extended public key, m/44'/coin'/wallet'.
+func parseAccountXpub(xpub string) (*hdkeychain.ExtendedKey, error) {
+	acct, err := hdkeychain.NewKeyFromString(xpub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXpub, err.Error())
+	}
+
+	if acct.IsPrivate() {
+		acct.Zero()
+
+		return nil, fmt.Errorf("%w: key is private", ErrInvalidXpub)
+	}
+
+	if acct.Depth() != accountDepth || acct.ChildIndex() < hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("%w: depth %d, child %d", ErrInvalidXpub, acct.Depth(), acct.ChildIndex())
+	}
+
+	return acct, nil
+}
+
+// PublicAddress generates the address for 'wallet', flg and address number using public derivation only, so it
+// works on watch-only wallets too. As public derivation cannot produce hardened keys, the address is the one of
+// AddressBIP44, m/44'/coin'/wallet'/flg/addrNum, and addrNum must be below 2^31.
+func (w *HdWallet) PublicAddress(wallet uint32, flg uint8, addrNum uint32) (addr []byte, err error) {
+	if addrNum >= hardened {
+		return nil, ErrIndexOutOfRange
+	}
+
+	tmpW, err := w.derive(wallet, flg, addrNum, addrNum)
+	if err != nil {
+		return nil, err
+	}
+	defer tmpW.Zero()
+
+	pub, err := tmpW.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return w.encodeAddress(pub), nil
+}
+
+// WatchOnly reports whether the wallet only holds extended public keys.
+func (w *HdWallet) WatchOnly() bool {
+	return w.accounts != nil
+}
+
+// publicAccount returns the extended public key of the account of wallet.
+func (w *HdWallet) publicAccount(wallet uint32) (*hdkeychain.ExtendedKey, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return nil, ErrClosed
+	}
+
+	if err := w.policy.allowDerivation(wallet, 0); err != nil {
+		return nil, err
+	}
+
+	acct, err := w.account(wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	if acct.IsPrivate() {
+		defer acct.Zero()
+	}
+
+	pub, err := acct.Neuter()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	// Neuter shares the chain code and key buffers with acct, so return a copy that zeroing acct, or closing a
+	// watch-only wallet holding it, does not affect.
+	pub, err = hdkeychain.NewKeyFromString(pub.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w ", ErrInternal, err)
+	}
+
+	return pub, nil
+}
+
+// account returns the key of the account of wallet: private, or public for watch-only wallets.
+func (w *HdWallet) account(wallet uint32) (*hdkeychain.ExtendedKey, error) {
+	if wallet >= hardened && !w.lenient {
+		return nil, ErrIndexOutOfRange
+	}
+
+	if w.accounts == nil {
+		return deriveChild(w.key, Path{hardened + purpose, hardened + w.coin, hardened + wallet}, 2)
+	}
+
+	acct, ok := w.accounts[wallet]
+	if !ok {
+		return nil, fmt.Errorf("%w: wallet %d", ErrAccountNotAvailable, wallet)
+	}
+
+	return acct, nil
+}
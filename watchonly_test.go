@@ -0,0 +1,119 @@
+package hd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPublicAddress(t *testing.T) {
+	w := testWallet(t)
+
+	xpub, err := w.ExportXpub(2)
+	if err != nil {
+		t.Fatalf("ExportXpub %e", err)
+	}
+
+	neutered, err := w.Neuter(0, 2)
+	if err != nil {
+		t.Fatalf("Neuter %e", err)
+	}
+
+	loaded, err := FromXpub(xpub)
+	if err != nil {
+		t.Fatalf("FromXpub %e", err)
+	}
+
+	if !neutered.WatchOnly() || !loaded.WatchOnly() || w.WatchOnly() {
+		t.Errorf("WatchOnly does not match")
+	}
+
+	for _, flg := range []uint8{External, Change} {
+		for i := uint32(0); i < 5; i++ {
+			addrExp, _, _, err := w.AddressBIP44(2, flg, i)
+			if err != nil {
+				t.Fatalf("AddressBIP44 %e", err)
+			}
+
+			for name, ww := range map[string]*HdWallet{"full": w, "neutered": neutered, "loaded": loaded} {
+				if addr, err := ww.PublicAddress(2, flg, i); err != nil || !bytes.Equal(addr, addrExp) {
+					t.Errorf("%s: address %d/%d does not match. Got:%x, expected:%x, err:%v", name, flg, i, addr,
+						addrExp, err)
+				}
+			}
+		}
+	}
+
+	// watch-only wallets hold no private keys nor other accounts
+	for name, ww := range map[string]*HdWallet{"neutered": neutered, "loaded": loaded} {
+		if _, _, _, err = ww.Address(2, External, 0); !errors.Is(err, ErrWatchOnly) {
+			t.Errorf("%s: Address. Expected ErrWatchOnly, got %v", name, err)
+		}
+
+		if _, _, _, err = ww.AddressBIP44(2, External, 0); !errors.Is(err, ErrWatchOnly) {
+			t.Errorf("%s: AddressBIP44. Expected ErrWatchOnly, got %v", name, err)
+		}
+
+		if _, err = ww.PublicAddress(1, External, 0); !errors.Is(err, ErrAccountNotAvailable) {
+			t.Errorf("%s: PublicAddress. Expected ErrAccountNotAvailable, got %v", name, err)
+		}
+
+		if exported, err := ww.ExportXpub(2); err != nil || exported != xpub {
+			t.Errorf("%s: ExportXpub does not match. Got:%s, err:%v", name, exported, err)
+		}
+	}
+
+	if _, err = w.PublicAddress(2, External, hardened); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	// closing a neutered copy leaves the loaded wallet usable
+	copied, _ := loaded.Neuter(2)
+	_ = copied.Close()
+
+	if _, err = loaded.PublicAddress(2, External, 0); err != nil {
+		t.Errorf("PublicAddress after closing a copy: %v", err)
+	}
+}
+
+func TestWatchOnlyLimits(t *testing.T) {
+	w := testWallet(t, WithLimits(Limits{MaxIndex: 5, AllowedAccounts: []uint32{0}}))
+
+	if _, err := w.ExportXpub(1); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("ExportXpub. Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	if _, err := w.Neuter(0, 1); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("Neuter. Expected ErrPolicyExceeded, got %v", err)
+	}
+
+	wo, err := w.Neuter(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = wo.PublicAddress(0, External, 5); err != nil {
+		t.Errorf("PublicAddress: %v", err)
+	}
+
+	if _, err = wo.PublicAddress(0, External, 1000); !errors.Is(err, ErrPolicyExceeded) {
+		t.Errorf("PublicAddress. Expected ErrPolicyExceeded, got %v", err)
+	}
+}
+
+func TestFromXpubErrors(t *testing.T) {
+	w := testWallet(t)
+
+	branch, _ := w.key.Neuter()
+
+	for i, s := range []string{
+		"",
+		"xpub-not-base58",
+		w.key.String(),  // private
+		branch.String(), // m/44'/60', not an account
+	} {
+		if _, err := FromXpub(s); !errors.Is(err, ErrInvalidXpub) {
+			t.Errorf("%d: expected ErrInvalidXpub, got %v", i, err)
+		}
+	}
+}